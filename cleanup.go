@@ -0,0 +1,149 @@
+package rthrest
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+//CleanupResult : The results deleted by CleanupOldResults. With dry run, they are the results which would be deleted
+type CleanupResult struct {
+	JobIDs              []string
+	ReportExtractionIDs []string
+	//Errors contains the errors of the results which can't be deleted. The other results are still deleted
+	Errors []error
+}
+
+//OnDemandScheduleNamePrefix : The prefix of ScheduleName of the report extractions created by the on demand requests, e.g. ExtractRaw
+const OnDemandScheduleNamePrefix = "_OnD_"
+
+//CleanupOptions : defined type for the scope of CleanupOldResults.
+//By default, only the report extractions of the on demand requests (ScheduleName starting with OnDemandScheduleNamePrefix) are deleted,
+//so the outputs of the schedules which may not be downloaded yet are kept
+type CleanupOptions struct {
+	//DryRun lists the results which would be deleted without deleting them
+	DryRun bool
+	//ReportExtractionFilter is the $filter expression of the listing of the report extractions, e.g. ScheduleName eq '_OnD_0x05ddad0f66cb2f86'.
+	//Only the report extractions returned by the filter are deleted
+	ReportExtractionFilter string
+	//IncludeScheduled deletes the report extractions of the schedules as well
+	IncludeScheduled bool
+}
+
+//DeleteRawExtractionResult : Delete the result of ExtractRaw from Extractions/RawExtractionResults('jobId')
+func (c *Client) DeleteRawExtractionResult(jobID string) error {
+	return c.delete(GetRawExtractionResultURL(c.URL, jobID))
+}
+
+//DeleteReportExtraction : Delete the report extraction and its files from Extractions/ReportExtractions('id')
+func (c *Client) DeleteReportExtraction(reportExtractionID string) error {
+	return c.delete(GetReportExtractionURL(c.URL, reportExtractionID))
+}
+
+//CleanupExtraction : Delete the result of ExtractRaw and the report extraction of the job from the server.
+//It is called after the file is downloaded and verified. Empty IDs are skipped and the results which are already deleted are ignored
+func (c *Client) CleanupExtraction(jobID string, reportExtractionID string) error {
+	if jobID != "" {
+		log.Printf("Delete RawExtractionResult: %s\n", jobID)
+		if err := ignoreNotFound(c.DeleteRawExtractionResult(jobID)); err != nil {
+			return err
+		}
+	}
+	if reportExtractionID != "" {
+		log.Printf("Delete ReportExtraction: %s\n", reportExtractionID)
+		if err := ignoreNotFound(c.DeleteReportExtraction(reportExtractionID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//ignoreNotFound : Return nil if the error is HTTP 404
+func ignoreNotFound(err error) error {
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == 404 {
+		return nil
+	}
+	return err
+}
+
+//ListRawExtractionResults : List the results of ExtractRaw which remain on the server from Extractions/RawExtractionResults.
+//All pages are returned and the query can be nil
+func (c *Client) ListRawExtractionResults(query *ODataQuery) ([]RawExtractionResult, error) {
+	var results []RawExtractionResult
+	if err := c.getAllPages(GetRawExtractionResultsURL(c.URL), query, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+//ListReportExtractions : List the report extractions of the user from Extractions/ReportExtractions.
+//All pages are returned and the query can be nil
+func (c *Client) ListReportExtractions(query *ODataQuery) ([]ReportExtraction, error) {
+	var extractions []ReportExtraction
+	if err := c.getAllPages(GetReportExtractionsURL(c.URL), query, &extractions); err != nil {
+		return nil, err
+	}
+	return extractions, nil
+}
+
+//rawExtractionResultTime : Return the time of the result from 'Extraction finished' line of its notes. It returns nil if the notes don't contain the time.
+//The other times in the notes are local times without the time zone so they are not used
+func rawExtractionResultTime(result *RawExtractionResult) *time.Time {
+	return result.ParseNotes().ExtractionFinished
+}
+
+//CleanupOldResults : Delete the results of ExtractRaw and the report extractions in the scope of options which are created before olderThan.
+//The time of RawExtractionResult is taken from its notes and the results without the time are kept.
+//With options.DryRun, nothing is deleted and the result contains the IDs which would be deleted
+func (c *Client) CleanupOldResults(olderThan time.Time, options CleanupOptions) (*CleanupResult, error) {
+	cleanup := &CleanupResult{}
+
+	results, err := c.ListRawExtractionResults(nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		created := rawExtractionResultTime(&results[i])
+		if created == nil || !created.Before(olderThan) {
+			continue
+		}
+		log.Printf("RawExtractionResult: %s, %s\n", results[i].JobID, created.Format(time.RFC3339))
+		if !options.DryRun {
+			if err := ignoreNotFound(c.DeleteRawExtractionResult(results[i].JobID)); err != nil {
+				cleanup.Errors = append(cleanup.Errors, fmt.Errorf("job %s: %w", results[i].JobID, err))
+				continue
+			}
+		}
+		cleanup.JobIDs = append(cleanup.JobIDs, results[i].JobID)
+	}
+
+	var query *ODataQuery
+	if options.ReportExtractionFilter != "" {
+		query = NewODataQuery().WithFilter(options.ReportExtractionFilter)
+	}
+	extractions, err := c.ListReportExtractions(query)
+	if err != nil {
+		return cleanup, err
+	}
+	for i := range extractions {
+		created := extractions[i].ExtractionDateUtc
+		if created == nil || !created.Before(olderThan) {
+			continue
+		}
+		if !options.IncludeScheduled && !strings.HasPrefix(extractions[i].ScheduleName, OnDemandScheduleNamePrefix) {
+			continue
+		}
+		log.Printf("ReportExtraction: %s, %s\n", extractions[i].ReportExtractionId, created.Format(time.RFC3339))
+		if !options.DryRun {
+			if err := ignoreNotFound(c.DeleteReportExtraction(extractions[i].ReportExtractionId)); err != nil {
+				cleanup.Errors = append(cleanup.Errors, fmt.Errorf("report extraction %s: %w", extractions[i].ReportExtractionId, err))
+				continue
+			}
+		}
+		cleanup.ReportExtractionIDs = append(cleanup.ReportExtractionIDs, extractions[i].ReportExtractionId)
+	}
+	return cleanup, nil
+}
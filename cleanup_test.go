@@ -0,0 +1,156 @@
+package rthrest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+//testCleanupServer : The server of RawExtractionResults and ReportExtractions of testCleanupData. The status of DELETE is taken from deleteStatus by the path.
+//The deleted paths and the $filter of the listing of ReportExtractions are recorded
+type testCleanupServer struct {
+	*httptest.Server
+	mutex        sync.Mutex
+	deleteStatus map[string]int
+	deleted      []string
+	filters      []string
+}
+
+func newTestCleanupServer(t *testing.T) *testCleanupServer {
+	results, extractions := testCleanupData()
+	s := &testCleanupServer{deleteStatus: make(map[string]int)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		path := strings.TrimPrefix(r.URL.Path, "/Extractions/")
+		switch {
+		case r.Method == "DELETE":
+			status := s.deleteStatus[path]
+			if status == 0 {
+				status = 204
+			}
+			if status == 204 {
+				s.deleted = append(s.deleted, path)
+			}
+			w.WriteHeader(status)
+		case path == "RawExtractionResults":
+			json.NewEncoder(w).Encode(map[string]interface{}{"value": results})
+		case path == "ReportExtractions":
+			s.filters = append(s.filters, r.URL.Query().Get("$filter"))
+			json.NewEncoder(w).Encode(map[string]interface{}{"value": extractions})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+//testCleanupData : Return the results and the report extractions which are 1 and 30 days old
+func testCleanupData() ([]RawExtractionResult, []ReportExtraction) {
+	now := time.Now().UTC()
+	finished := func(daysAgo int) []string {
+		return []string{"Extraction finished at " + now.AddDate(0, 0, -daysAgo).Format(notesTimeLayout) + " UTC, with servers: tm04n03 (9.156 secs)"}
+	}
+	extracted := func(daysAgo int) *time.Time {
+		t := now.AddDate(0, 0, -daysAgo)
+		return &t
+	}
+	results := []RawExtractionResult{
+		{JobID: "0x01", Notes: finished(30)},
+		{JobID: "0x02", Notes: finished(30)},
+		{JobID: "0x03", Notes: finished(1)},
+		//The local time without UTC is not used
+		{JobID: "0x04", Notes: []string{"Processing started at " + now.AddDate(0, 0, -30).Format(notesTimeLayout)}},
+	}
+	extractions := []ReportExtraction{
+		{ReportExtractionId: "1", ScheduleName: "_OnD_0x01", ExtractionDateUtc: extracted(30)},
+		{ReportExtractionId: "2", ScheduleName: "_OnD_0x02", ExtractionDateUtc: extracted(30)},
+		{ReportExtractionId: "3", ScheduleName: "_OnD_0x03", ExtractionDateUtc: extracted(1)},
+		//The output of the schedule may not be downloaded yet
+		{ReportExtractionId: "4", ScheduleName: "Daily VOD.L", ExtractionDateUtc: extracted(30)},
+	}
+	return results, extractions
+}
+
+func TestCleanupOldResults(t *testing.T) {
+	olderThan := time.Now().AddDate(0, 0, -7)
+	tests := []struct {
+		name        string
+		options     CleanupOptions
+		wantJobs    []string
+		wantReports []string
+		wantDeleted []string
+		wantErrors  int
+	}{
+		{
+			name:        "dry run",
+			options:     CleanupOptions{DryRun: true},
+			wantJobs:    []string{"0x01", "0x02"},
+			wantReports: []string{"1", "2"},
+		},
+		{
+			//0x02 is already deleted (404) and the report extraction 2 can't be deleted (500)
+			name:        "delete",
+			wantJobs:    []string{"0x01", "0x02"},
+			wantReports: []string{"1"},
+			wantDeleted: []string{"RawExtractionResults('0x01')", "ReportExtractions('1')"},
+			wantErrors:  1,
+		},
+		{
+			name:        "include scheduled",
+			options:     CleanupOptions{DryRun: true, IncludeScheduled: true},
+			wantJobs:    []string{"0x01", "0x02"},
+			wantReports: []string{"1", "2", "4"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestCleanupServer(t)
+			server.deleteStatus["RawExtractionResults('0x02')"] = 404
+			server.deleteStatus["ReportExtractions('2')"] = 500
+			client := NewClient(server.Client(), server.URL+"/", false)
+
+			cleanup, err := client.CleanupOldResults(olderThan, test.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(cleanup.JobIDs, ",") != strings.Join(test.wantJobs, ",") {
+				t.Errorf("JobIDs = %v, want %v", cleanup.JobIDs, test.wantJobs)
+			}
+			if strings.Join(cleanup.ReportExtractionIDs, ",") != strings.Join(test.wantReports, ",") {
+				t.Errorf("ReportExtractionIDs = %v, want %v", cleanup.ReportExtractionIDs, test.wantReports)
+			}
+			sort.Strings(server.deleted)
+			if strings.Join(server.deleted, ",") != strings.Join(test.wantDeleted, ",") {
+				t.Errorf("deleted = %v, want %v", server.deleted, test.wantDeleted)
+			}
+			if len(cleanup.Errors) != test.wantErrors {
+				t.Errorf("Errors = %v, want %d", cleanup.Errors, test.wantErrors)
+			} else if test.wantErrors > 0 && !strings.Contains(cleanup.Errors[0].Error(), "report extraction 2") {
+				t.Errorf("Errors = %v", cleanup.Errors)
+			}
+		})
+	}
+}
+
+func TestCleanupOldResultsFilter(t *testing.T) {
+	server := newTestCleanupServer(t)
+	client := NewClient(server.Client(), server.URL+"/", false)
+
+	options := CleanupOptions{DryRun: true, ReportExtractionFilter: "ScheduleName eq '_OnD_0x01'"}
+	if _, err := client.CleanupOldResults(time.Now(), options); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.filters) != 1 || server.filters[0] != options.ReportExtractionFilter {
+		t.Errorf("$filter = %q, want %q", server.filters, options.ReportExtractionFilter)
+	}
+	if len(server.deleted) != 0 {
+		t.Errorf("dry run deleted %v", server.deleted)
+	}
+}
@@ -0,0 +1,254 @@
+package rthrest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+//Client : defined type for the connection to Tick History REST API.
+//Headers contains the common headers of the requests including the Authorization header added by RequestToken or SetToken.
+//Limiter limits the total bandwidth of all streams and downloads created by the client.
+//DirectDownload downloads the files from AWS by using X-Direct-Download header. The AWS URL is resolved for each download
+//and the file is downloaded from DSS if the redirect is not available.
+//Progress is used by the downloaders created by the client if it is not nil
+type Client struct {
+	HTTPClient     *http.Client
+	URL            string
+	Headers        map[string]string
+	Tracing        bool
+	Limiter        *BandwidthLimiter
+	DirectDownload bool
+	Progress       ProgressReporter
+}
+
+//NewClient : Create a Client for the Tick History REST API URL, e.g. https://selectapi.datascope.refinitiv.com/RestApi/v1/
+func NewClient(httpClient *http.Client, rthapiurl string, tracing bool) *Client {
+	return &Client{
+		HTTPClient: httpClient,
+		URL:        rthapiurl,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+			"Prefer":       "respond-async",
+		},
+		Tracing: tracing,
+	}
+}
+
+//SetToken : Set the Authorization header with the token
+func (c *Client) SetToken(token string) {
+	c.Headers["Authorization"] = "Token " + token
+}
+
+//RequestToken : Send Authentication/RequestToken request and set the Authorization header with the retrieved token
+func (c *Client) RequestToken(username string, password string) (string, error) {
+	loginreq, err := json.Marshal(struct {
+		Credentials Credential
+	}{
+		Credentials: Credential{
+			Username: username,
+			Password: password,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := HTTPPost(c.HTTPClient, GetRequestTokenURL(c.URL), bytes.NewBuffer(loginreq), c.Headers, c.Tracing)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != 200 {
+		return "", &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
+	}
+
+	tokenResponse := &RequestTokenResponse{}
+	if err = json.Unmarshal(body, tokenResponse); err != nil {
+		return "", err
+	}
+	c.SetToken(tokenResponse.Value)
+	return tokenResponse.Value, nil
+}
+
+//getJSON : Send GET request to the URL and decode the JSON response to v
+func (c *Client) getJSON(url string, v interface{}) error {
+	resp, err := HTTPGet(c.HTTPClient, url, c.Headers, c.Tracing)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
+	}
+	return json.Unmarshal(body, v)
+}
+
+//postJSON : Send POST request with the JSON of in and decode the JSON response to out. out can be nil
+func (c *Client) postJSON(url string, in interface{}, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	resp, err := HTTPPost(c.HTTPClient, url, bytes.NewBuffer(data), c.Headers, c.Tracing)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 && resp.StatusCode != 201 {
+		return &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
+	}
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
+
+//putJSON : Send PUT request with the JSON of in
+func (c *Client) putJSON(url string, in interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	resp, err := HTTPPut(c.HTTPClient, url, bytes.NewBuffer(data), c.Headers, c.Tracing)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 && resp.StatusCode != 204 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
+	}
+	return nil
+}
+
+//delete : Send DELETE request to the URL
+func (c *Client) delete(url string) error {
+	resp, err := HTTPDelete(c.HTTPClient, url, c.Headers, c.Tracing)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 && resp.StatusCode != 204 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
+	}
+	return nil
+}
+
+//NewDownloader : Create a Downloader which uses the HTTP client, headers, tracing, bandwidth limiter, direct download and progress settings of the Client
+func (c *Client) NewDownloader() *Downloader {
+	d := NewDownloader(c.HTTPClient, c.Headers, c.Tracing)
+	d.Limiter = c.Limiter
+	d.DirectDownload = c.DirectDownload
+	d.VerifyETag = c.DirectDownload
+	if c.Progress != nil {
+		d.Progress = c.Progress
+	}
+	return d
+}
+
+//GetRawExtractionResult : Get the JobId and the notes of the completed job from Extractions/RawExtractionResults('jobId').
+//It is used to download the result of the job again, e.g. after the process restarts, without a new extraction.
+//IdentifierValidationErrors are only returned by ExtractRaw so they are empty
+func (c *Client) GetRawExtractionResult(jobID string) (*RawExtractionResult, error) {
+	result := &RawExtractionResult{}
+	if err := c.getJSON(GetRawExtractionResultURL(c.URL, jobID), result); err != nil {
+		return nil, fmt.Errorf("job %s: %w", jobID, err)
+	}
+	if result.JobID == "" {
+		result.JobID = jobID
+	}
+	return result, nil
+}
+
+//GetRawExtractionResultNotes : Get the notes of the completed job and parse them. See ParseExtractionNotes
+func (c *Client) GetRawExtractionResultNotes(jobID string) (*ExtractionNotes, error) {
+	result, err := c.GetRawExtractionResult(jobID)
+	if err != nil {
+		return nil, err
+	}
+	return result.ParseNotes(), nil
+}
+
+//StreamOptions : defined type for the options of Client.Stream.
+//Decompress returns the decompressed (csv) content instead of the gzip content.
+//DirectDownload reads the content from the AWS URL retrieved with X-Direct-Download header. It is always enabled by Client.DirectDownload
+type StreamOptions struct {
+	Decompress     bool
+	DirectDownload bool
+}
+
+//Stream : Return the content of RawExtractionResults('jobId')/$value as io.ReadCloser without writing it to a file.
+//The caller must close the returned reader. The request is canceled when the context is done
+func (c *Client) Stream(ctx context.Context, jobID string, options StreamOptions) (io.ReadCloser, error) {
+	return c.streamURL(ctx, GetRawExtractionResultGetDefaultStreamURL(c.URL, jobID), options)
+}
+
+//streamURL : Send GET request to the DSS URL (or the AWS URL) and return the response body
+func (c *Client) streamURL(ctx context.Context, url string, options StreamOptions) (io.ReadCloser, error) {
+	src := &downloadSource{
+		client:  c.HTTPClient,
+		url:     url,
+		headers: c.Headers,
+		tracing: c.Tracing,
+		direct:  options.DirectDownload || c.DirectDownload,
+	}
+	resp, err := src.get(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
+	}
+	var body io.ReadCloser = resp.Body
+	if c.Limiter != nil {
+		body = &limitedReadCloser{Reader: c.Limiter.Reader(ctx, resp.Body), Closer: resp.Body}
+	}
+	if !options.Decompress {
+		return body, nil
+	}
+
+	gzipReader, err := gzip.NewReader(body)
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+	return &gzipReadCloser{Reader: gzipReader, body: body}, nil
+}
+
+//gzipReadCloser : io.ReadCloser which reads the decompressed content and closes both gzip reader and the response body
+type gzipReadCloser struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+//Close : Close the gzip reader and the response body
+func (r *gzipReadCloser) Close() error {
+	r.Reader.Close()
+	return r.body.Close()
+}
@@ -0,0 +1,146 @@
+package rthrest
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+//coverageFieldNames : The content fields requested by GetInstrumentCoverage
+var coverageFieldNames = []string{"Domain", "History Start", "History End"}
+
+//coverageDateLayouts : The date-only layouts of History Start and History End values. The timestamps are parsed as RFC3339
+var coverageDateLayouts = []string{"2006-01-02", "2006/01/02", "01/02/2006"}
+
+//InstrumentCoverage : The Tick History coverage window of the instrument. HistoryStart and HistoryEnd are nil if the value is not available.
+//If History End is a date, HistoryEnd is the last nanosecond of that day so the data of the last day is covered
+type InstrumentCoverage struct {
+	RIC          string
+	Domain       string
+	HistoryStart *time.Time
+	HistoryEnd   *time.Time
+}
+
+//Overlaps : Return true if the coverage window overlaps the range from start to end. Missing History Start or History End is not limited
+func (c *InstrumentCoverage) Overlaps(start time.Time, end time.Time) bool {
+	if c.HistoryStart != nil && c.HistoryStart.After(end) {
+		return false
+	}
+	if c.HistoryEnd != nil && c.HistoryEnd.Before(start) {
+		return false
+	}
+	return true
+}
+
+//Clamp : Return the part of the range from start to end which is covered by the instrument
+func (c *InstrumentCoverage) Clamp(start time.Time, end time.Time) (time.Time, time.Time) {
+	if c.HistoryStart != nil && c.HistoryStart.After(start) {
+		start = *c.HistoryStart
+	}
+	if c.HistoryEnd != nil && c.HistoryEnd.Before(end) {
+		end = *c.HistoryEnd
+	}
+	return start, end
+}
+
+//parseCoverageTime : Parse the value of History Start or History End. It returns nil if the value is empty or invalid.
+//If endOfDay is true, the date without the time is moved to the end of the day
+func parseCoverageTime(value string, endOfDay bool) *time.Time {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return &t
+	}
+	for _, layout := range coverageDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			if endOfDay {
+				t = t.Add(24*time.Hour - time.Nanosecond)
+			}
+			return &t
+		}
+	}
+	return nil
+}
+
+//GetInstrumentCoverage : Return the Tick History coverage window and the domain of each instrument in the list.
+//It runs TickHistoryMarketDepthExtractionRequest with Domain, History Start and History End fields in PreviewModeContentEnum
+//so the full extraction is not charged. The first row of each RIC is used
+func (c *Client) GetInstrumentCoverage(ctx context.Context, identifierList InstrumentIdentifierList) ([]InstrumentCoverage, error) {
+	start := time.Date(1996, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Now().UTC()
+	request := &TickHistoryMarketDepthExtractionRequest{
+		ContentFieldNames: coverageFieldNames,
+		IdentifierList:    identifierList,
+	}
+	request.Condition.View = ViewOptionsNormalizedLL2Enum
+	request.Condition.ReportDateRangeType = ReportDateRangeTypeRangeEnum
+	request.Condition.QueryStartDate = &start
+	request.Condition.QueryEndDate = &end
+
+	preview, err := c.PreviewContent(ctx, request, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var coverage []InstrumentCoverage
+	found := make(map[string]bool)
+	for _, row := range preview.Maps() {
+		ric := row["RIC"]
+		if ric == "" || found[ric] {
+			continue
+		}
+		found[ric] = true
+		coverage = append(coverage, InstrumentCoverage{
+			RIC:          ric,
+			Domain:       row["Domain"],
+			HistoryStart: parseCoverageTime(row["History Start"], false),
+			HistoryEnd:   parseCoverageTime(row["History End"], true),
+		})
+	}
+	return coverage, nil
+}
+
+//ClampRequestToCoverage : Remove the instruments which have no data from QueryStartDate to QueryEndDate and clamp the query range
+//to the coverage of the remaining instruments. The instruments without coverage are kept and don't limit the range.
+//It is used with ReportDateRangeTypeRangeEnum only. It returns the removed instruments
+func ClampRequestToCoverage(request *TickHistoryMarketDepthExtractionRequest, coverage []InstrumentCoverage) []InstrumentIdentifier {
+	condition := &request.Condition
+	if condition.ReportDateRangeType != ReportDateRangeTypeRangeEnum || condition.QueryStartDate == nil || condition.QueryEndDate == nil {
+		return nil
+	}
+	byRIC := make(map[string]*InstrumentCoverage)
+	for i := range coverage {
+		byRIC[strings.ToUpper(coverage[i].RIC)] = &coverage[i]
+	}
+
+	start, end := *condition.QueryStartDate, *condition.QueryEndDate
+	var kept, removed []InstrumentIdentifier
+	var newStart, newEnd time.Time
+	unknown := false
+	for _, identifier := range request.IdentifierList.InstrumentIdentifiers {
+		c, ok := byRIC[strings.ToUpper(identifier.Identifier)]
+		if !ok {
+			unknown = true
+			kept = append(kept, identifier)
+			continue
+		}
+		if !c.Overlaps(start, end) {
+			removed = append(removed, identifier)
+			continue
+		}
+		kept = append(kept, identifier)
+		s, e := c.Clamp(start, end)
+		if newStart.IsZero() || s.Before(newStart) {
+			newStart = s
+		}
+		if newEnd.IsZero() || e.After(newEnd) {
+			newEnd = e
+		}
+	}
+
+	request.IdentifierList.InstrumentIdentifiers = kept
+	if !unknown && len(kept) > 0 {
+		condition.QueryStartDate = &newStart
+		condition.QueryEndDate = &newEnd
+	}
+	return removed
+}
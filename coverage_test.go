@@ -0,0 +1,116 @@
+package rthrest
+
+import (
+	"testing"
+	"time"
+)
+
+//testTime : Parse the RFC3339 time of the test
+func testTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestParseCoverageTime(t *testing.T) {
+	tests := []struct {
+		value    string
+		endOfDay bool
+		want     string
+	}{
+		{value: "2017-08-22", want: "2017-08-22T00:00:00Z"},
+		{value: "2017-08-22", endOfDay: true, want: "2017-08-22T23:59:59.999999999Z"},
+		{value: "2017/08/22", endOfDay: true, want: "2017-08-22T23:59:59.999999999Z"},
+		{value: "08/22/2017", endOfDay: true, want: "2017-08-22T23:59:59.999999999Z"},
+		//The time is kept if the value is a timestamp
+		{value: "2017-08-22T09:30:00Z", endOfDay: true, want: "2017-08-22T09:30:00Z"},
+		{value: " 2017-08-22T09:30:00.123456789Z ", want: "2017-08-22T09:30:00.123456789Z"},
+		{value: ""},
+		{value: "n/a", endOfDay: true},
+	}
+	for _, test := range tests {
+		got := parseCoverageTime(test.value, test.endOfDay)
+		if test.want == "" {
+			if got != nil {
+				t.Errorf("parseCoverageTime(%q) = %v, want nil", test.value, got)
+			}
+			continue
+		}
+		if want := testTime(t, test.want); got == nil || !got.Equal(want) {
+			t.Errorf("parseCoverageTime(%q, %v) = %v, want %v", test.value, test.endOfDay, got, want)
+		}
+	}
+}
+
+func TestInstrumentCoverageOverlapsAndClamp(t *testing.T) {
+	//History Start and History End are dates, as returned by the preview
+	coverage := InstrumentCoverage{
+		RIC:          "VOD.L",
+		HistoryStart: parseCoverageTime("2017-08-01", false),
+		HistoryEnd:   parseCoverageTime("2017-08-22", true),
+	}
+	tests := []struct {
+		name      string
+		start     string
+		end       string
+		overlaps  bool
+		wantStart string
+		wantEnd   string
+	}{
+		{name: "inside", start: "2017-08-10T00:00:00Z", end: "2017-08-11T00:00:00Z", overlaps: true, wantStart: "2017-08-10T00:00:00Z", wantEnd: "2017-08-11T00:00:00Z"},
+		{name: "same day as History End", start: "2017-08-22T09:00:00Z", end: "2017-08-22T17:00:00Z", overlaps: true, wantStart: "2017-08-22T09:00:00Z", wantEnd: "2017-08-22T17:00:00Z"},
+		{name: "same day as History Start", start: "2017-08-01T09:00:00Z", end: "2017-08-01T17:00:00Z", overlaps: true, wantStart: "2017-08-01T09:00:00Z", wantEnd: "2017-08-01T17:00:00Z"},
+		{name: "after History End", start: "2017-08-22T09:00:00Z", end: "2017-08-25T00:00:00Z", overlaps: true, wantStart: "2017-08-22T09:00:00Z", wantEnd: "2017-08-22T23:59:59.999999999Z"},
+		{name: "before History Start", start: "2017-07-01T00:00:00Z", end: "2017-08-02T00:00:00Z", overlaps: true, wantStart: "2017-08-01T00:00:00Z", wantEnd: "2017-08-02T00:00:00Z"},
+		{name: "starts at the end of History End", start: "2017-08-22T23:59:59.999999999Z", end: "2017-08-23T00:00:00Z", overlaps: true, wantStart: "2017-08-22T23:59:59.999999999Z", wantEnd: "2017-08-22T23:59:59.999999999Z"},
+		{name: "starts on the next day", start: "2017-08-23T00:00:00Z", end: "2017-08-24T00:00:00Z"},
+		{name: "ends before History Start", start: "2017-07-01T00:00:00Z", end: "2017-07-31T23:59:59Z"},
+	}
+	for _, test := range tests {
+		start, end := testTime(t, test.start), testTime(t, test.end)
+		if got := coverage.Overlaps(start, end); got != test.overlaps {
+			t.Errorf("%s: Overlaps = %v, want %v", test.name, got, test.overlaps)
+		}
+		if !test.overlaps {
+			continue
+		}
+		gotStart, gotEnd := coverage.Clamp(start, end)
+		if !gotStart.Equal(testTime(t, test.wantStart)) || !gotEnd.Equal(testTime(t, test.wantEnd)) {
+			t.Errorf("%s: Clamp = %v - %v, want %s - %s", test.name, gotStart, gotEnd, test.wantStart, test.wantEnd)
+		}
+	}
+}
+
+func TestClampRequestToCoverage(t *testing.T) {
+	coverage := []InstrumentCoverage{
+		{RIC: "VOD.L", HistoryStart: parseCoverageTime("2010-01-04", false), HistoryEnd: parseCoverageTime("2017-08-22", true)},
+		{RIC: "IBM.N", HistoryStart: parseCoverageTime("1996-01-02", false), HistoryEnd: parseCoverageTime("2017-08-21", true)},
+		{RIC: "OLD.L", HistoryStart: parseCoverageTime("2001-01-02", false), HistoryEnd: parseCoverageTime("2005-06-30", true)},
+	}
+	start := testTime(t, "2017-08-22T09:00:00Z")
+	end := testTime(t, "2017-08-31T00:00:00Z")
+	request := &TickHistoryMarketDepthExtractionRequest{}
+	request.Condition.ReportDateRangeType = ReportDateRangeTypeRangeEnum
+	request.Condition.QueryStartDate = &start
+	request.Condition.QueryEndDate = &end
+	request.IdentifierList.InstrumentIdentifiers = []InstrumentIdentifier{
+		{Identifier: "VOD.L", IdentifierType: "Ric"},
+		{Identifier: "IBM.N", IdentifierType: "Ric"},
+		{Identifier: "OLD.L", IdentifierType: "Ric"},
+	}
+
+	removed := ClampRequestToCoverage(request, coverage)
+	//VOD.L has the data on the last day of the coverage
+	if len(removed) != 2 || removed[0].Identifier != "IBM.N" || removed[1].Identifier != "OLD.L" {
+		t.Errorf("removed = %+v, want IBM.N and OLD.L", removed)
+	}
+	if identifiers := request.IdentifierList.InstrumentIdentifiers; len(identifiers) != 1 || identifiers[0].Identifier != "VOD.L" {
+		t.Errorf("kept = %+v, want VOD.L", identifiers)
+	}
+	if !request.Condition.QueryStartDate.Equal(start) || !request.Condition.QueryEndDate.Equal(testTime(t, "2017-08-22T23:59:59.999999999Z")) {
+		t.Errorf("query range = %v - %v", request.Condition.QueryStartDate, request.Condition.QueryEndDate)
+	}
+}
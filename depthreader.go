@@ -0,0 +1,366 @@
+package rthrest
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//MalformedLinePolicy : This is an enumeration for the way MarketDepthReader handles the lines which can't be parsed
+type MalformedLinePolicy int
+
+//Available Enumerations for MalformedLinePolicy
+const (
+	MalformedLineFailEnum MalformedLinePolicy = iota
+	MalformedLineWarnEnum
+	MalformedLineSkipEnum
+)
+
+//Enumeration String of malformedLinePolicy enumeration used by String and ParseMalformedLinePolicy
+var malformedLinePolicy = [...]string{
+	"fail",
+	"warn",
+	"skip",
+}
+
+//String : It uses malformedLinePolicy string array variable to convert int (enum) to string
+func (p MalformedLinePolicy) String() string {
+	return malformedLinePolicy[p]
+}
+
+//ParseMalformedLinePolicy : Convert the policy name (fail, warn, skip) to MalformedLinePolicy
+func ParseMalformedLinePolicy(name string) (MalformedLinePolicy, error) {
+	for i, v := range malformedLinePolicy {
+		if strings.EqualFold(v, name) {
+			return MalformedLinePolicy(i), nil
+		}
+	}
+	return MalformedLineFailEnum, fmt.Errorf("unknown malformed line policy: %q", name)
+}
+
+//depthLevelReg : The column of the depth level in the header, e.g. L1-BidPrice
+var depthLevelReg = regexp.MustCompile(`^L([0-9]+)-(BidPrice|BidSize|AskPrice|AskSize|BuyNo|SellNo)$`)
+
+//DepthLevel : The bid and ask of one level of the market depth. Empty values are 0
+type DepthLevel struct {
+	BidPrice float64
+	BidSize  float64
+	AskPrice float64
+	AskSize  float64
+	BuyNo    int64
+	SellNo   int64
+}
+
+//MarketDepthRecord : One row of the csv.gz output of TickHistoryMarketDepthExtractionRequest.
+//Levels[0] is level 1. Fields contains the non-empty values of the other columns, e.g. Source RIC
+type MarketDepthRecord struct {
+	RIC       string
+	Domain    string
+	DateTime  time.Time
+	GMTOffset time.Duration
+	Type      string
+	Levels    []DepthLevel
+	Fields    map[string]string
+}
+
+//MalformedLineError : The error of the line which can't be parsed. Line is the line number in the file starting from 1.
+//If the record has a quoted field with line breaks, Line is the first line of the record
+type MalformedLineError struct {
+	Line   int
+	Record []string
+	Err    error
+}
+
+//Error : Return the line number and the reason
+func (e *MalformedLineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err.Error())
+}
+
+//Unwrap : Return the reason
+func (e *MalformedLineError) Unwrap() error {
+	return e.Err
+}
+
+//depthColumn : The position of a level column in the record
+type depthColumn struct {
+	level int
+	name  string
+}
+
+//MarketDepthReader : The streaming reader of the csv.gz (or csv) output of TickHistoryMarketDepthExtractionRequest.
+//The columns are found from the header row so the reader works with any ContentFieldNames and NumberOfLevels.
+//Policy decides what Read does with malformed lines. Skipped is the number of lines skipped by MalformedLineWarnEnum and MalformedLineSkipEnum
+type MarketDepthReader struct {
+	Policy  MalformedLinePolicy
+	Skipped int
+
+	reader    *csv.Reader
+	closer    io.Closer
+	header    []string
+	ric       int
+	domain    int
+	dateTime  int
+	gmtOffset int
+	recType   int
+	levels    map[int]depthColumn
+	numLevels int
+}
+
+//NewMarketDepthReader : Create MarketDepthReader and read the header row. The input is decompressed if it starts with the gzip header
+func NewMarketDepthReader(r io.Reader) (*MarketDepthReader, error) {
+	buffered := bufio.NewReader(r)
+	var input io.Reader = buffered
+	var closer io.Closer
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		input = gzipReader
+		closer = gzipReader
+	}
+
+	d := &MarketDepthReader{
+		reader:    csv.NewReader(input),
+		closer:    closer,
+		ric:       -1,
+		domain:    -1,
+		dateTime:  -1,
+		gmtOffset: -1,
+		recType:   -1,
+		levels:    make(map[int]depthColumn),
+	}
+	d.reader.FieldsPerRecord = -1
+
+	header, err := d.reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			err = fmt.Errorf("no header row")
+		}
+		return nil, err
+	}
+	d.parseHeader(header)
+	return d, nil
+}
+
+//OpenMarketDepthFile : Open the file, e.g. output_jobId.csv.gz, and create MarketDepthReader. The caller must call Close
+func OpenMarketDepthFile(fileName string) (*MarketDepthReader, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	d, err := NewMarketDepthReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if d.closer != nil {
+		d.closer = &multiCloser{d.closer, file}
+	} else {
+		d.closer = file
+	}
+	return d, nil
+}
+
+//multiCloser : Close the gzip reader and the file
+type multiCloser []io.Closer
+
+//Close : Close all closers and return the first error
+func (m *multiCloser) Close() error {
+	var first error
+	for _, c := range *m {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+//Close : Close the gzip reader and the file opened by OpenMarketDepthFile
+func (d *MarketDepthReader) Close() error {
+	if d.closer == nil {
+		return nil
+	}
+	return d.closer.Close()
+}
+
+//Header : Return the column names of the header row. The '#' before the first column name is removed
+func (d *MarketDepthReader) Header() []string {
+	return d.header
+}
+
+//NumberOfLevels : Return the number of levels found in the header
+func (d *MarketDepthReader) NumberOfLevels() int {
+	return d.numLevels
+}
+
+//parseHeader : Find the columns of the record in the header
+func (d *MarketDepthReader) parseHeader(header []string) {
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "#")
+	}
+	d.header = header
+	for i, column := range header {
+		switch strings.TrimSpace(column) {
+		case "RIC":
+			d.ric = i
+		case "Domain":
+			d.domain = i
+		case "Date-Time":
+			d.dateTime = i
+		case "GMT Offset":
+			d.gmtOffset = i
+		case "Type":
+			d.recType = i
+		default:
+			if m := depthLevelReg.FindStringSubmatch(strings.TrimSpace(column)); m != nil {
+				level, _ := strconv.Atoi(m[1])
+				if level < 1 {
+					continue
+				}
+				d.levels[i] = depthColumn{level: level, name: m[2]}
+				if level > d.numLevels {
+					d.numLevels = level
+				}
+			}
+		}
+	}
+}
+
+//Read : Return the next record. It returns io.EOF at the end of the file.
+//Malformed lines return MalformedLineError with MalformedLineFailEnum. With the other policies they are skipped and the next record is returned
+func (d *MarketDepthReader) Read() (*MarketDepthRecord, error) {
+	for {
+		row, err := d.reader.Read()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err == nil {
+			var record *MarketDepthRecord
+			if record, err = d.parseRecord(row); err == nil {
+				return record, nil
+			}
+		}
+		line := 0
+		if parseErr, ok := err.(*csv.ParseError); ok {
+			line = parseErr.StartLine
+		} else if row == nil {
+			return nil, err
+		} else {
+			line, _ = d.reader.FieldPos(0)
+		}
+
+		malformed := &MalformedLineError{Line: line, Record: row, Err: err}
+		switch d.Policy {
+		case MalformedLineWarnEnum:
+			log.Printf("Warning: skip malformed line: %s\n", malformed.Error())
+			d.Skipped++
+		case MalformedLineSkipEnum:
+			d.Skipped++
+		default:
+			return nil, malformed
+		}
+	}
+}
+
+//parseRecord : Convert the row to MarketDepthRecord
+func (d *MarketDepthReader) parseRecord(row []string) (*MarketDepthRecord, error) {
+	if len(row) != len(d.header) {
+		return nil, fmt.Errorf("%d columns, expected %d", len(row), len(d.header))
+	}
+	record := &MarketDepthRecord{Levels: make([]DepthLevel, d.numLevels)}
+	for i, value := range row {
+		value = strings.TrimSpace(value)
+		switch i {
+		case d.ric:
+			record.RIC = value
+		case d.domain:
+			record.Domain = value
+		case d.dateTime:
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid Date-Time: %q", value)
+			}
+			record.DateTime = t
+		case d.gmtOffset:
+			offset, err := parseGMTOffset(value)
+			if err != nil {
+				return nil, err
+			}
+			record.GMTOffset = offset
+		case d.recType:
+			record.Type = value
+		default:
+			column, ok := d.levels[i]
+			if !ok {
+				if value != "" {
+					if record.Fields == nil {
+						record.Fields = make(map[string]string)
+					}
+					record.Fields[d.header[i]] = value
+				}
+				continue
+			}
+			if value == "" {
+				continue
+			}
+			if err := setDepthLevel(&record.Levels[column.level-1], column.name, value); err != nil {
+				return nil, fmt.Errorf("invalid %s: %q", d.header[i], value)
+			}
+		}
+	}
+	return record, nil
+}
+
+//setDepthLevel : Set the value of the level column
+func setDepthLevel(level *DepthLevel, name string, value string) error {
+	var err error
+	switch name {
+	case "BidPrice":
+		level.BidPrice, err = strconv.ParseFloat(value, 64)
+	case "BidSize":
+		level.BidSize, err = strconv.ParseFloat(value, 64)
+	case "AskPrice":
+		level.AskPrice, err = strconv.ParseFloat(value, 64)
+	case "AskSize":
+		level.AskSize, err = strconv.ParseFloat(value, 64)
+	case "BuyNo":
+		level.BuyNo, err = strconv.ParseInt(value, 10, 64)
+	case "SellNo":
+		level.SellNo, err = strconv.ParseInt(value, 10, 64)
+	}
+	return err
+}
+
+//parseGMTOffset : Parse the GMT Offset column, e.g. +2 or -3:30. The empty value is 0
+func parseGMTOffset(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	sign := time.Duration(1)
+	hours, minutes := strings.TrimPrefix(value, "+"), "0"
+	if strings.HasPrefix(hours, "-") {
+		sign = -1
+		hours = hours[1:]
+	}
+	if i := strings.Index(hours, ":"); i >= 0 {
+		hours, minutes = hours[:i], hours[i+1:]
+	}
+	h, err := strconv.Atoi(hours)
+	if err != nil {
+		return 0, fmt.Errorf("invalid GMT Offset: %q", value)
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil {
+		return 0, fmt.Errorf("invalid GMT Offset: %q", value)
+	}
+	return sign * (time.Duration(h)*time.Hour + time.Duration(m)*time.Minute), nil
+}
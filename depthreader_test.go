@@ -0,0 +1,215 @@
+package rthrest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+//testDepthCSV : The output of TickHistoryMarketDepthExtractionRequest with 2 levels. The record on line 3 has a quoted field
+//with a line break so the malformed records are on line 5 (invalid price) and line 6 (bare quote)
+const testDepthCSV = "#RIC,Domain,Date-Time,GMT Offset,Type,L1-BidPrice,L1-BidSize,L1-BuyNo,L1-AskPrice,L1-AskSize,L1-SellNo,L2-BidPrice,L2-BidSize,L2-AskPrice,L2-AskSize,Qualifiers\n" +
+	"VOD.L,Market Depth,2017-08-22T07:00:00.123456789Z,+1,Market Depth,231.5,1000,3,231.6,2500,4,231.4,500,231.7,,\n" +
+	"VOD.L,Market Depth,2017-08-22T07:00:01.000000000Z,+1,Market Depth,231.5,1200,3,231.6,2500,4,,,,,\"first\nsecond\"\n" +
+	"VOD.L,Market Depth,2017-08-22T07:00:02.000000000Z,+1,Market Depth,n/a,1200,3,231.6,2500,4,,,,,\n" +
+	"VOD.L,Market Depth,2017-08-22T07:00:03.000000000Z,+1,Market Depth,231.5,1200,3,231.6,2500,4,,,,,bare\"quote\n" +
+	"VOD.L,Market Depth,2017-08-22T07:00:04.000000000Z,-3:30,Market Depth,231.5,1300,3,231.6,2500,4,,,,,\n"
+
+//testDepthInputs : Return the plain and the gzip input of the content
+func testDepthInputs(t *testing.T, content string) map[string][]byte {
+	t.Helper()
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return map[string][]byte{"plain": []byte(content), "gzip": compressed.Bytes()}
+}
+
+func TestParseGMTOffset(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		err   bool
+	}{
+		{value: "", want: 0},
+		{value: "0", want: 0},
+		{value: "+2", want: 2 * time.Hour},
+		{value: "9", want: 9 * time.Hour},
+		{value: "-5", want: -5 * time.Hour},
+		{value: "+5:30", want: 5*time.Hour + 30*time.Minute},
+		{value: "-3:30", want: -(3*time.Hour + 30*time.Minute)},
+		{value: "GMT", err: true},
+		{value: "+2:xx", err: true},
+	}
+	for _, test := range tests {
+		got, err := parseGMTOffset(test.value)
+		if test.err {
+			if err == nil {
+				t.Errorf("parseGMTOffset(%q) = %v, want error", test.value, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("parseGMTOffset(%q) = %v, %v, want %v", test.value, got, err, test.want)
+		}
+	}
+}
+
+func TestMarketDepthReaderHeader(t *testing.T) {
+	d, err := NewMarketDepthReader(strings.NewReader(testDepthCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.NumberOfLevels() != 2 {
+		t.Errorf("NumberOfLevels = %d, want 2", d.NumberOfLevels())
+	}
+	header := d.Header()
+	if len(header) != 16 || header[0] != "RIC" || header[15] != "Qualifiers" {
+		t.Errorf("Header = %q", header)
+	}
+	if d.ric != 0 || d.domain != 1 || d.dateTime != 2 || d.gmtOffset != 3 || d.recType != 4 {
+		t.Errorf("columns = %d %d %d %d %d", d.ric, d.domain, d.dateTime, d.gmtOffset, d.recType)
+	}
+	if column := d.levels[13]; column.level != 2 || column.name != "AskPrice" {
+		t.Errorf("levels[13] = %+v", column)
+	}
+
+	if _, err := NewMarketDepthReader(strings.NewReader("")); err == nil {
+		t.Error("NewMarketDepthReader of the empty input: want error")
+	}
+}
+
+func TestMarketDepthReaderRead(t *testing.T) {
+	for name, input := range testDepthInputs(t, testDepthCSV) {
+		d, err := NewMarketDepthReader(bytes.NewReader(input))
+		if err != nil {
+			t.Fatalf("%s: %s", name, err.Error())
+		}
+		d.Policy = MalformedLineSkipEnum
+
+		var records []*MarketDepthRecord
+		for {
+			record, err := d.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: %s", name, err.Error())
+			}
+			records = append(records, record)
+		}
+		if len(records) != 3 || d.Skipped != 2 {
+			t.Fatalf("%s: %d records, Skipped = %d, want 3 and 2", name, len(records), d.Skipped)
+		}
+
+		first := records[0]
+		if first.RIC != "VOD.L" || first.Domain != "Market Depth" || first.Type != "Market Depth" || first.GMTOffset != time.Hour ||
+			!first.DateTime.Equal(testTime(t, "2017-08-22T07:00:00.123456789Z")) {
+			t.Errorf("%s: records[0] = %+v", name, first)
+		}
+		wantLevels := []DepthLevel{
+			{BidPrice: 231.5, BidSize: 1000, BuyNo: 3, AskPrice: 231.6, AskSize: 2500, SellNo: 4},
+			{BidPrice: 231.4, BidSize: 500, AskPrice: 231.7},
+		}
+		if len(first.Levels) != 2 || first.Levels[0] != wantLevels[0] || first.Levels[1] != wantLevels[1] {
+			t.Errorf("%s: records[0].Levels = %+v, want %+v", name, first.Levels, wantLevels)
+		}
+		if first.Fields != nil {
+			t.Errorf("%s: records[0].Fields = %v, want nil", name, first.Fields)
+		}
+		if qualifiers := records[1].Fields["Qualifiers"]; qualifiers != "first\nsecond" {
+			t.Errorf("%s: records[1] Qualifiers = %q", name, qualifiers)
+		}
+		if records[2].GMTOffset != -(3*time.Hour+30*time.Minute) || records[2].Levels[1] != (DepthLevel{}) {
+			t.Errorf("%s: records[2] = %+v", name, records[2])
+		}
+	}
+}
+
+func TestMarketDepthReaderPolicy(t *testing.T) {
+	tests := []struct {
+		policy  MalformedLinePolicy
+		records int
+		lines   []int
+	}{
+		{policy: MalformedLineFailEnum, records: 2, lines: []int{5}},
+		{policy: MalformedLineWarnEnum, records: 3},
+		{policy: MalformedLineSkipEnum, records: 3},
+	}
+	for _, test := range tests {
+		for name, input := range testDepthInputs(t, testDepthCSV) {
+			d, err := NewMarketDepthReader(bytes.NewReader(input))
+			if err != nil {
+				t.Fatalf("%s %s: %s", test.policy, name, err.Error())
+			}
+			d.Policy = test.policy
+
+			records := 0
+			var lines []int
+			for {
+				_, err := d.Read()
+				if err == io.EOF {
+					break
+				}
+				var malformed *MalformedLineError
+				if errors.As(err, &malformed) {
+					lines = append(lines, malformed.Line)
+					//MalformedLineFailEnum stops at the first malformed line
+					break
+				}
+				if err != nil {
+					t.Fatalf("%s %s: %s", test.policy, name, err.Error())
+				}
+				records++
+			}
+			if records != test.records || len(lines) != len(test.lines) {
+				t.Errorf("%s %s: %d records, malformed lines %v, want %d and %v", test.policy, name, records, lines, test.records, test.lines)
+				continue
+			}
+			for i := range lines {
+				if lines[i] != test.lines[i] {
+					t.Errorf("%s %s: malformed lines %v, want %v", test.policy, name, lines, test.lines)
+				}
+			}
+			if test.policy != MalformedLineFailEnum && d.Skipped != 2 {
+				t.Errorf("%s %s: Skipped = %d, want 2", test.policy, name, d.Skipped)
+			}
+		}
+	}
+}
+
+func TestMarketDepthReaderMalformedLine(t *testing.T) {
+	//Each malformed record is returned with MalformedLineFailEnum and the reader continues with the next record
+	d, err := NewMarketDepthReader(strings.NewReader(testDepthCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []int
+	for {
+		_, err := d.Read()
+		if err == io.EOF {
+			break
+		}
+		if malformed, ok := err.(*MalformedLineError); ok {
+			lines = append(lines, malformed.Line)
+			if malformed.Err == nil || !strings.HasPrefix(malformed.Error(), "line ") {
+				t.Errorf("Error = %q", malformed.Error())
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(lines) != 2 || lines[0] != 5 || lines[1] != 6 {
+		t.Errorf("malformed lines = %v, want [5 6]", lines)
+	}
+}
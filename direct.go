@@ -0,0 +1,141 @@
+package rthrest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync"
+)
+
+//downloadSource : The URL and headers used by the requests of one download.
+//With X-Direct-Download, the AWS URL is resolved from the DSS URL on the first request and it is resolved again when AWS returns 403,
+//e.g. the presigned URL is expired. If DSS responds without the redirect, the file is downloaded from the DSS URL.
+//Network errors and retryable status codes of the resolve request are returned so the request is retried like other requests
+type downloadSource struct {
+	client  *http.Client
+	url     string
+	headers map[string]string
+	tracing bool
+	direct  bool
+
+	mutex    sync.Mutex
+	awsURL   string
+	fallback bool
+}
+
+//newDownloadSource : Create the source of the download URL by using the settings of Downloader
+func (d *Downloader) newDownloadSource(url string) *downloadSource {
+	return &downloadSource{
+		client:  d.Client,
+		url:     url,
+		headers: d.Headers,
+		tracing: d.Tracing,
+		direct:  d.DirectDownload,
+	}
+}
+
+//current : Return the URL and headers of the next request. The AWS URL is resolved if it is not resolved yet.
+//No DSS headers are returned with the AWS URL because AWS rejects them
+func (s *downloadSource) current(ctx context.Context) (string, map[string]string, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.direct && !s.fallback && s.awsURL == "" {
+		if err := s.resolve(ctx); err != nil {
+			return "", nil, false, err
+		}
+	}
+	if !s.direct || s.fallback {
+		return s.url, s.headers, false, nil
+	}
+	return s.awsURL, nil, true, nil
+}
+
+//refresh : Resolve the AWS URL again if it is still the expired URL. Other parts may have refreshed it already
+func (s *downloadSource) refresh(ctx context.Context, expiredURL string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.awsURL != expiredURL {
+		return nil
+	}
+	log.Printf("X-Direct-Download: AWS URL is rejected, resolve it again\n")
+	return s.resolve(ctx)
+}
+
+//resolve : Resolve the AWS URL. It switches to the DSS URL if DSS responds without the redirect.
+//The other errors are returned and the AWS URL is resolved again by the next request. The mutex must be locked
+func (s *downloadSource) resolve(ctx context.Context) error {
+	awsURL, err := resolveDirectDownloadURL(ctx, s.client, s.url, s.headers, s.tracing)
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) && !statusErr.Retryable() {
+		log.Printf("%s, download from DSS\n", err.Error())
+		s.fallback = true
+		s.awsURL = ""
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("AWS: %s\n", awsURL)
+	s.awsURL = awsURL
+	return nil
+}
+
+//get : Send GET request with the Range header (if it is not empty) to the current URL of the source.
+//If AWS returns 403, the AWS URL is resolved again and the request is sent once more
+func (s *downloadSource) get(ctx context.Context, rangeValue string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		url, headers, isAWS, err := s.current(ctx)
+		if err != nil {
+			return nil, err
+		}
+		newHeaders := make(map[string]string)
+		for k, v := range headers {
+			newHeaders[k] = v
+		}
+		if rangeValue != "" {
+			newHeaders["Range"] = rangeValue
+		}
+
+		resp, err := HTTPGetWithContext(ctx, s.client, url, newHeaders, s.tracing)
+		if err != nil || !isAWS || resp.StatusCode != 403 || attempt > 0 {
+			return resp, err
+		}
+		resp.Body.Close()
+		//The next request is sent to the new AWS URL, or to the DSS URL if the redirect is not available any more
+		if err = s.refresh(ctx, url); err != nil {
+			return nil, err
+		}
+	}
+}
+
+//resolveDirectDownloadURL : Send GET request with X-Direct-Download header and return the AWS URL in the Location header of the redirect
+func resolveDirectDownloadURL(ctx context.Context, client *http.Client, url string, headers map[string]string, tracing bool) (string, error) {
+	newHeaders := make(map[string]string)
+	for k, v := range headers {
+		newHeaders[k] = v
+	}
+	newHeaders["X-Direct-Download"] = "true"
+
+	//The redirect must not be followed, otherwise the DSS headers are sent to AWS
+	noRedirectClient := *client
+	noRedirectClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	resp, err := HTTPGetWithContext(ctx, &noRedirectClient, url, newHeaders, tracing)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 302 || resp.Header.Get("Location") == "" {
+		//The body may be the whole file if DSS doesn't redirect the request
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 5000))
+		return "", fmt.Errorf("X-Direct-Download is not available: %w", &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)})
+	}
+	return resp.Header.Get("Location"), nil
+}
@@ -0,0 +1,123 @@
+package rthrest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+//testDirectDownload : The DSS and AWS servers of X-Direct-Download. dssResolve returns the status code of the n-th X-Direct-Download request,
+//302 redirects to AWS and 200 serves the file. awsStatus returns the status code of the n-th AWS request, 200 serves the file
+type testDirectDownload struct {
+	content    []byte
+	dssResolve func(n int) int
+	awsStatus  func(n int) int
+
+	mutex       sync.Mutex
+	resolves    int
+	awsRequests int
+	dssRequests int
+	dss         *httptest.Server
+	aws         *httptest.Server
+}
+
+func newTestDirectDownload(t *testing.T, content []byte, dssResolve func(n int) int, awsStatus func(n int) int) *testDirectDownload {
+	s := &testDirectDownload{content: content, dssResolve: dssResolve, awsStatus: awsStatus}
+	s.aws = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		s.awsRequests++
+		status := s.awsStatus(s.awsRequests)
+		s.mutex.Unlock()
+		if status != 200 {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("ETag", `"aws"`)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(s.content))
+	}))
+	s.dss = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		status := 200
+		if r.Header.Get("X-Direct-Download") != "" {
+			s.resolves++
+			status = s.dssResolve(s.resolves)
+		} else {
+			s.dssRequests++
+		}
+		s.mutex.Unlock()
+		switch status {
+		case 302:
+			http.Redirect(w, r, s.aws.URL, http.StatusFound)
+		case 200:
+			w.Header().Set("ETag", `"dss"`)
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(s.content))
+		default:
+			w.WriteHeader(status)
+		}
+	}))
+	t.Cleanup(s.aws.Close)
+	t.Cleanup(s.dss.Close)
+	return s
+}
+
+func TestDirectDownloadRetryResolve(t *testing.T) {
+	content := testGzipContent(t, 128*1024)
+	//The first resolve request fails with 503 and the next one is redirected
+	server := newTestDirectDownload(t, content,
+		func(n int) int {
+			if n == 1 {
+				return 503
+			}
+			return 302
+		},
+		func(n int) int { return 200 })
+	outFileName := filepath.Join(t.TempDir(), "output.csv.gz")
+	downloader := NewDownloader(server.dss.Client(), nil, false)
+	downloader.Progress = nil
+	downloader.RetryBackoff = time.Millisecond
+	downloader.DirectDownload = true
+
+	if err := downloader.ConcurrentDownload(server.dss.URL, outFileName, 2, int64(len(content))); err != nil {
+		t.Fatal(err)
+	}
+	checkDownloadedFile(t, outFileName, content)
+	if server.dssRequests != 0 || server.awsRequests != 2 {
+		t.Errorf("DSS requests = %d, AWS requests = %d, want 0, 2", server.dssRequests, server.awsRequests)
+	}
+}
+
+func TestDirectDownloadFallbackETag(t *testing.T) {
+	content := testGzipContent(t, 128*1024)
+	//AWS serves the first part and rejects the others. DSS doesn't redirect the second resolve request so the download switches to DSS
+	server := newTestDirectDownload(t, content,
+		func(n int) int {
+			if n == 1 {
+				return 302
+			}
+			return 200
+		},
+		func(n int) int {
+			if n == 1 {
+				return 200
+			}
+			return 403
+		})
+	outFileName := filepath.Join(t.TempDir(), "output.csv.gz")
+	downloader := NewDownloader(server.dss.Client(), nil, false)
+	downloader.Progress = nil
+	downloader.MaxRetries = 0
+	downloader.DirectDownload = true
+
+	//The ETag of DSS is different from AWS but it must not be reported as the changed file
+	if err := downloader.ConcurrentDownload(server.dss.URL, outFileName, 2, int64(len(content))); err != nil {
+		t.Fatal(err)
+	}
+	checkDownloadedFile(t, outFileName, content)
+	if server.dssRequests != 1 || server.awsRequests != 2 {
+		t.Errorf("DSS requests = %d, AWS requests = %d, want 1, 2", server.dssRequests, server.awsRequests)
+	}
+}
@@ -0,0 +1,537 @@
+package rthrest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Downloader : defined type for the settings used by the resumable and concurrent downloads.
+//Failed parts are retried with exponential backoff, starting from RetryBackoff and limited by MaxRetryBackoff.
+//The completed file is verified before it is renamed to the output file name.
+//VerifyGzip decompresses the whole .gz file and VerifyETag compares the MD5 with the S3 ETag of X-Direct-Download.
+//A file which fails the verification is handled by CorruptFileAction.
+//Progress receives the progress of the download every ProgressInterval.
+//Limiter limits the bandwidth of all parts. It can be shared with other downloads and streams.
+//MaxConnections, MinPartSize, ProbeSize and TargetPartDuration are used by AutoDownload to choose the number of parts.
+//DirectDownload downloads the file from the AWS URL resolved with X-Direct-Download header. The URL must be the DSS URL, e.g. RawExtractionResults('jobId')/$value.
+//CheckpointSize is the number of bytes of a part which are written between the checkpoints of ConcurrentDownload. If it is 0, the part is checkpointed only when the request ends
+type Downloader struct {
+	Client             *http.Client
+	Headers            map[string]string
+	Tracing            bool
+	MaxRetries         int
+	RetryBackoff       time.Duration
+	MaxRetryBackoff    time.Duration
+	VerifyGzip         bool
+	VerifyETag         bool
+	CorruptFileAction  CorruptFileAction
+	Progress           ProgressReporter
+	ProgressInterval   time.Duration
+	Limiter            *BandwidthLimiter
+	MaxConnections     int
+	MinPartSize        int64
+	ProbeSize          int64
+	TargetPartDuration time.Duration
+	DirectDownload     bool
+	CheckpointSize     int64
+}
+
+//NewDownloader : Create a Downloader with the default retry settings
+func NewDownloader(client *http.Client, headers map[string]string, tracing bool) *Downloader {
+	return &Downloader{
+		Client:             client,
+		Headers:            headers,
+		Tracing:            tracing,
+		MaxRetries:         5,
+		RetryBackoff:       2 * time.Second,
+		MaxRetryBackoff:    time.Minute,
+		VerifyGzip:         true,
+		CorruptFileAction:  CorruptFileQuarantineEnum,
+		Progress:           NewLogProgressReporter(5 * time.Second),
+		ProgressInterval:   time.Second,
+		MaxConnections:     8,
+		MinPartSize:        16 * 1024 * 1024,
+		ProbeSize:          1024 * 1024,
+		TargetPartDuration: 10 * time.Second,
+		CheckpointSize:     4 * 1024 * 1024,
+	}
+}
+
+//HTTPStatusError : The error returned when the server responds with an unexpected HTTP status code
+type HTTPStatusError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("Status Code: %s\n%s ", e.Status, e.Body)
+}
+
+//Retryable : Return true if the request may succeed when it is sent again
+func (e *HTTPStatusError) Retryable() bool {
+	return e.StatusCode == 408 || e.StatusCode == 429 || e.StatusCode >= 500
+}
+
+//Download : Download the full file to a temporary file (outFileName + PartialFileSuffix) and rename it to outFileName when it is completed.
+//If the temporary file exists from the previous run, the download is resumed from the end of this file with 'Range: bytes=N-' header.
+//expectedSize is the size of the file. If it is 0, the size is taken from Content-Range or Content-Length header.
+//When the download fails, the temporary file is kept so the next call can resume it.
+//The response is rejected if Content-Range or Content-Length doesn't match the remaining bytes of the expected size.
+//The completed file is verified against the expected size and ETag before it is renamed
+func (d *Downloader) Download(url string, outFileName string, expectedSize int64) error {
+	return d.download(d.newDownloadSource(url), outFileName, expectedSize)
+}
+
+//download : Download the file from the source. See Download
+func (d *Downloader) download(src *downloadSource, outFileName string, expectedSize int64) error {
+	partialFileName := outFileName + PartialFileSuffix
+
+	var offset int64
+	if fi, err := os.Stat(partialFileName); err == nil {
+		offset = fi.Size()
+	}
+	if expectedSize > 0 && offset == expectedSize {
+		log.Printf("Download File: %s is already completed\n", partialFileName)
+		if err := d.verifyDownload(partialFileName, outFileName, expectedSize, ""); err != nil {
+			return err
+		}
+		return finishDownload(partialFileName, outFileName)
+	}
+	if expectedSize > 0 && offset > expectedSize {
+		//The partial file doesn't belong to this download
+		offset = 0
+	}
+
+	log.Printf("Download File: %s, resume from %d\n", outFileName, offset)
+	rangeValue := ""
+	if offset > 0 {
+		rangeValue = fmt.Sprintf("bytes=%d-", offset)
+	}
+
+	resp, err := src.get(context.Background(), rangeValue)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	total := expectedSize
+	switch resp.StatusCode {
+	case 206:
+		start, _, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return err
+		}
+		if start != offset {
+			return fmt.Errorf("unexpected Content-Range: %s, requested offset: %d", resp.Header.Get("Content-Range"), offset)
+		}
+		if total > 0 && size >= 0 && size != total {
+			return fmt.Errorf("unexpected Content-Range: %s, expected size: %d", resp.Header.Get("Content-Range"), total)
+		}
+		if total <= 0 {
+			total = size
+		}
+	case 200:
+		//The server doesn't support the range request so the file is downloaded from the beginning
+		offset = 0
+		if total <= 0 {
+			total = resp.ContentLength
+		}
+	case 416:
+		//The range is not satisfiable when the partial file already contains the whole file
+		_, _, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err == nil && size == offset {
+			if err = d.verifyDownload(partialFileName, outFileName, size, ""); err != nil {
+				return err
+			}
+			return finishDownload(partialFileName, outFileName)
+		}
+		return fmt.Errorf("Status Code: %s, partial file: %s, size: %d", resp.Status, partialFileName, offset)
+	default:
+		body, _ := ioutil.ReadAll(resp.Body)
+		return &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
+	}
+
+	//A shorter or longer body would leave a truncated file or a file mixed with another file
+	if total > 0 && resp.ContentLength >= 0 && resp.ContentLength != total-offset {
+		return fmt.Errorf("unexpected Content-Length: %d, expected: %d bytes from %d of %d", resp.ContentLength, total-offset, offset, total)
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY
+	if offset > 0 {
+		flags |= os.O_APPEND
+	} else {
+		flags |= os.O_TRUNC
+	}
+	out, err := os.OpenFile(partialFileName, flags, 0644)
+	if err != nil {
+		return err
+	}
+
+	progress := d.startProgress(outFileName, total, offset)
+	_, err = io.Copy(io.MultiWriter(out, progress), d.Limiter.Reader(context.Background(), resp.Body))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		var fi os.FileInfo
+		if fi, err = os.Stat(partialFileName); err == nil && total > 0 && fi.Size() < total {
+			err = fmt.Errorf("incomplete download: %s, size: %d, expected: %d", partialFileName, fi.Size(), total)
+		}
+	}
+	progress.stop(err)
+	if err != nil {
+		return err
+	}
+	//The ETag covers the whole file only if the whole file is downloaded by this response
+	etag := ""
+	if offset == 0 {
+		etag = resp.Header.Get("ETag")
+	}
+	if err = d.verifyDownload(partialFileName, outFileName, total, etag); err != nil {
+		return err
+	}
+	return finishDownload(partialFileName, outFileName)
+}
+
+//finishDownload : Rename the completed temporary file to outFileName and remove the temporary files left by the other download method,
+//e.g. the preallocated file and the checkpoint of an interrupted ConcurrentDownload when Download completes the file
+func finishDownload(tempFileName string, outFileName string) error {
+	if err := os.Rename(tempFileName, outFileName); err != nil {
+		return err
+	}
+	for _, suffix := range []string{PartialFileSuffix, PreallocatedFileSuffix, ManifestFileSuffix} {
+		os.Remove(outFileName + suffix)
+	}
+	return nil
+}
+
+//DownloadPart : defined type for a byte range of the concurrent download. Stop is inclusive.
+//Written is the number of bytes from Start which are already written to the output file
+type DownloadPart struct {
+	Index   int
+	Start   int64
+	Stop    int64
+	Written int64
+	Done    bool
+}
+
+//DownloadManifest : The checkpoint of the concurrent download. It is written to outFileName + ManifestFileSuffix
+//and it is used to download only the missing parts when the download is restarted
+//ETag is the ETag header of the first response from ETagHost. The parts with a different ETag are rejected because the file has changed.
+//AWS and DSS return different ETags for the same file, so the ETag is replaced when the download switches to another host
+type DownloadManifest struct {
+	FileName string
+	FileSize int64
+	ETag     string `json:",omitempty"`
+	ETagHost string `json:",omitempty"`
+	Parts    []DownloadPart
+}
+
+//ManifestFileSuffix : The suffix of the checkpoint file written by ConcurrentDownload
+const ManifestFileSuffix = ".manifest.json"
+
+//PreallocatedFileSuffix : The suffix of the temporary file written by ConcurrentDownload. The file has the full size before all parts are downloaded
+//so it must not be resumed by Download, which treats a PartialFileSuffix file of the full size as completed
+const PreallocatedFileSuffix = ".parts"
+
+//newDownloadManifest : Split the file into numOfConn parts. The last part contains the remaining bytes
+func newDownloadManifest(outFileName string, numOfConn int, fileSize int64) *DownloadManifest {
+	manifest := &DownloadManifest{FileName: outFileName, FileSize: fileSize}
+	partSize := fileSize / int64(numOfConn)
+	var fileOffset int64
+	for i := 1; i <= numOfConn; i++ {
+		stop := fileOffset + partSize - 1
+		if i == numOfConn {
+			stop = fileSize - 1
+		}
+		manifest.Parts = append(manifest.Parts, DownloadPart{
+			Index: i,
+			Start: fileOffset,
+			Stop:  stop,
+		})
+		fileOffset = stop + 1
+	}
+	return manifest
+}
+
+//loadDownloadManifest : Read the checkpoint of the previous run. It returns nil if there is no checkpoint,
+//the checkpoint is for a different file size or number of parts, or the partial output file is missing
+func loadDownloadManifest(outFileName string, numOfConn int, fileSize int64) *DownloadManifest {
+	data, err := ioutil.ReadFile(outFileName + ManifestFileSuffix)
+	if err != nil {
+		return nil
+	}
+	manifest := &DownloadManifest{}
+	if err = json.Unmarshal(data, manifest); err != nil {
+		log.Printf("Ignore invalid manifest %s: %s\n", outFileName+ManifestFileSuffix, err.Error())
+		return nil
+	}
+	if manifest.FileSize != fileSize || len(manifest.Parts) != numOfConn {
+		return nil
+	}
+	if fi, err := os.Stat(outFileName + PreallocatedFileSuffix); err != nil || fi.Size() != fileSize {
+		return nil
+	}
+	return manifest
+}
+
+//save : Write the checkpoint to a temporary file and rename it so the checkpoint is never half written
+func (m *DownloadManifest) save() error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	fileName := m.FileName + ManifestFileSuffix
+	if err = ioutil.WriteFile(fileName+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(fileName+".tmp", fileName)
+}
+
+//offsetWriter : io.Writer which writes to the file at the offset by using WriteAt. The offset is moved after each write
+type offsetWriter struct {
+	file   *os.File
+	offset int64
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+	n, err := w.file.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
+
+//concurrentDownload : The state shared by the goroutines of Downloader.ConcurrentDownload
+type concurrentDownload struct {
+	*Downloader
+	src      *downloadSource
+	file     *os.File
+	manifest *DownloadManifest
+	progress *progressTracker
+	mutex    sync.Mutex
+}
+
+//ConcurrentDownload : Download a file concurrently by the specified by the numOfConn. Filesize of the file is required.
+//All parts are written directly into a preallocated temporary file (outFileName + PreallocatedFileSuffix) which is renamed to outFileName on success.
+//Each part is retried individually and the progress of the parts is checkpointed to outFileName + ManifestFileSuffix.
+//If the download is restarted, only the missing byte ranges are downloaded. The checkpoint is removed on success
+func (d *Downloader) ConcurrentDownload(url string, outFileName string, numOfConn int, fileSize int64) error {
+	return d.concurrentDownload(d.newDownloadSource(url), outFileName, numOfConn, fileSize)
+}
+
+//concurrentDownload : Download the file from the source concurrently. See ConcurrentDownload
+func (d *Downloader) concurrentDownload(src *downloadSource, outFileName string, numOfConn int, fileSize int64) error {
+	if numOfConn < 1 {
+		numOfConn = 1
+	}
+	log.Printf("ConcurrentDownload: %s, conn=%d\n", outFileName, numOfConn)
+
+	partialFileName := outFileName + PreallocatedFileSuffix
+	manifest := loadDownloadManifest(outFileName, numOfConn, fileSize)
+	if manifest != nil {
+		log.Printf("ConcurrentDownload: resume %s from %s\n", outFileName, outFileName+ManifestFileSuffix)
+	} else {
+		manifest = newDownloadManifest(outFileName, numOfConn, fileSize)
+	}
+
+	file, err := os.OpenFile(partialFileName, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	//Preallocate the output file so every part can write at its own offset
+	if err = file.Truncate(fileSize); err != nil {
+		return err
+	}
+	if err = manifest.save(); err != nil {
+		return err
+	}
+
+	var written int64
+	for _, part := range manifest.Parts {
+		written += part.Written
+	}
+	state := &concurrentDownload{Downloader: d, src: src, file: file, manifest: manifest}
+	state.progress = d.startProgress(outFileName, fileSize, written)
+	var wg sync.WaitGroup
+	var errs []string
+
+	for i := range manifest.Parts {
+		part := manifest.Parts[i]
+		if part.Done {
+			log.Printf("Part %d: %d - %d is already downloaded\n", part.Index, part.Start, part.Stop)
+			continue
+		}
+		log.Printf("Part %d: %d - %d\n", part.Index, part.Start+part.Written, part.Stop)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := state.downloadPartWithRetry(i); err != nil {
+				state.mutex.Lock()
+				errs = append(errs, fmt.Sprintf("part %d: %s", state.manifest.Parts[i].Index, err.Error()))
+				state.mutex.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		err = fmt.Errorf("ConcurrentDownload: %s is incomplete, restart to download the missing parts\n%s", outFileName, strings.Join(errs, "\n"))
+	}
+	state.progress.stop(err)
+	if err != nil {
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+	if err = d.verifyDownload(partialFileName, outFileName, fileSize, manifest.ETag); err != nil {
+		os.Remove(outFileName + ManifestFileSuffix)
+		return err
+	}
+	return finishDownload(partialFileName, outFileName)
+}
+
+//downloadPartWithRetry : Download the part and retry with exponential backoff when it fails
+func (c *concurrentDownload) downloadPartWithRetry(i int) error {
+	backoff := c.RetryBackoff
+	index := c.manifest.Parts[i].Index
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = c.downloadPart(i)
+		if err == nil {
+			return nil
+		}
+		if statusErr, ok := err.(*HTTPStatusError); ok && !statusErr.Retryable() {
+			return err
+		}
+		if _, ok := err.(*IntegrityError); ok {
+			return err
+		}
+		if attempt >= c.MaxRetries {
+			return err
+		}
+		log.Printf("Part %d: %s, retry %d/%d in %s\n", index, err.Error(), attempt+1, c.MaxRetries, backoff)
+		time.Sleep(backoff)
+		backoff = backoff * 2
+		if c.MaxRetryBackoff > 0 && backoff > c.MaxRetryBackoff {
+			backoff = c.MaxRetryBackoff
+		}
+	}
+}
+
+//downloadPart : Download the remaining bytes of the part into the output file at the offset of the part.
+//The number of written bytes is checkpointed even if the download fails
+func (c *concurrentDownload) downloadPart(i int) error {
+	c.mutex.Lock()
+	part := c.manifest.Parts[i]
+	c.mutex.Unlock()
+
+	partSize := part.Stop - part.Start + 1
+	if part.Written >= partSize {
+		return c.checkpoint(i, 0, true)
+	}
+
+	resp, err := c.src.get(context.Background(), fmt.Sprintf("bytes=%d-%d", part.Start+part.Written, part.Stop))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 206 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode == 200 {
+			return &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: "the server doesn't support range requests"}
+		}
+		return &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
+	}
+
+	if err = c.checkETag(resp.Header.Get("ETag"), resp.Request.URL.Host); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	writer := &checkpointWriter{writer: &offsetWriter{file: c.file, offset: part.Start + part.Written}, state: c, index: i}
+	n, err := io.Copy(io.MultiWriter(writer, c.progress), io.LimitReader(c.Limiter.Reader(context.Background(), resp.Body), partSize-part.Written))
+	done := err == nil && part.Written+n == partSize
+	if checkpointErr := writer.flush(done); err == nil {
+		err = checkpointErr
+	}
+	if err != nil {
+		return err
+	}
+	if !done {
+		return fmt.Errorf("incomplete part: %d, size: %d, expected: %d", part.Index, part.Written+n, partSize)
+	}
+	log.Printf("Part %d: Download Completed, %d bytes in %s\n", part.Index, n, time.Since(start))
+	return nil
+}
+
+//checkpoint : Add the written bytes to the part and save the manifest
+func (c *concurrentDownload) checkpoint(i int, written int64, done bool) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.manifest.Parts[i].Written += written
+	c.manifest.Parts[i].Done = done
+	return c.manifest.save()
+}
+
+//checkpointWriter : io.Writer which writes the part into the output file and checkpoints the written bytes every CheckpointSize bytes,
+//so the bytes are not downloaded again if the process is killed in the middle of the part
+type checkpointWriter struct {
+	writer  *offsetWriter
+	state   *concurrentDownload
+	index   int
+	unsaved int64
+}
+
+func (w *checkpointWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.unsaved += int64(n)
+	if err == nil && w.state.CheckpointSize > 0 && w.unsaved >= w.state.CheckpointSize {
+		err = w.flush(false)
+	}
+	return n, err
+}
+
+//flush : Sync the output file and checkpoint the bytes written after the last checkpoint.
+//The file is synced first so the manifest never contains the bytes which are not on the disk
+func (w *checkpointWriter) flush(done bool) error {
+	if err := w.state.file.Sync(); err != nil {
+		return err
+	}
+	written := w.unsaved
+	w.unsaved = 0
+	return w.state.checkpoint(w.index, written, done)
+}
+
+//checkETag : Keep the ETag of the first response from the host in the manifest and reject the responses from the same host with a different ETag
+func (c *concurrentDownload) checkETag(etag string, host string) error {
+	if etag == "" {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.manifest.ETag == "" || c.manifest.ETagHost != host {
+		if c.manifest.ETag != "" {
+			log.Printf("ConcurrentDownload: the file is downloaded from %s, ETag: %s\n", host, etag)
+		}
+		c.manifest.ETag = etag
+		c.manifest.ETagHost = host
+		return nil
+	}
+	if c.manifest.ETag != etag {
+		return &IntegrityError{FileName: c.manifest.FileName, Reason: fmt.Sprintf("ETag changed from %s to %s", c.manifest.ETag, etag)}
+	}
+	return nil
+}
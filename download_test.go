@@ -0,0 +1,346 @@
+package rthrest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+//testFileServer : httptest server which serves the content with the range requests like DSS and AWS
+type testFileServer struct {
+	*httptest.Server
+	content []byte
+	etag    string
+
+	mutex sync.Mutex
+	//noRange ignores the Range header and returns the whole content with 200
+	noRange bool
+	//cutAt aborts the responses after the byte at this offset of the content is sent. It is not used if it is 0
+	cutAt int64
+	//ranges are the Range headers of the requests. An empty string is the request without Range
+	ranges []string
+}
+
+//newTestFileServer : Start the server of the content. The server is closed when the test ends
+func newTestFileServer(t *testing.T, content []byte) *testFileServer {
+	s := &testFileServer{content: content, etag: `"test-etag"`}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testFileServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	s.ranges = append(s.ranges, r.Header.Get("Range"))
+	noRange, cutAt := s.noRange, s.cutAt
+	s.mutex.Unlock()
+
+	if noRange {
+		r.Header.Del("Range")
+	}
+	w.Header().Set("ETag", s.etag)
+	var writer http.ResponseWriter = w
+	if cutAt > 0 {
+		start := int64(0)
+		fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start)
+		writer = &cutWriter{ResponseWriter: w, remaining: cutAt - start}
+	}
+	http.ServeContent(writer, r, "", time.Time{}, bytes.NewReader(s.content))
+}
+
+//setCutAt : Change cutAt of the next requests
+func (s *testFileServer) setCutAt(cutAt int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cutAt = cutAt
+}
+
+//requests : Return the Range headers received by the server and clear them
+func (s *testFileServer) requests() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	ranges := s.ranges
+	s.ranges = nil
+	return ranges
+}
+
+//cutWriter : http.ResponseWriter which aborts the connection after the remaining bytes are written
+type cutWriter struct {
+	http.ResponseWriter
+	remaining int64
+}
+
+func (w *cutWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > w.remaining {
+		w.ResponseWriter.Write(p[:w.remaining])
+		w.ResponseWriter.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}
+	w.remaining -= int64(len(p))
+	return w.ResponseWriter.Write(p)
+}
+
+//testGzipContent : Return the gzip stream of random text so the downloader can verify it
+func testGzipContent(t *testing.T, size int) []byte {
+	random := rand.New(rand.NewSource(1))
+	text := make([]byte, size)
+	for i := range text {
+		text[i] = byte('a' + random.Intn(26))
+	}
+	var buffer bytes.Buffer
+	writer, _ := gzip.NewWriterLevel(&buffer, gzip.NoCompression)
+	writer.Write(text)
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+//newTestDownloader : Create the downloader without the progress log and retry delay
+func newTestDownloader(s *testFileServer) *Downloader {
+	d := NewDownloader(s.Client(), nil, false)
+	d.Progress = nil
+	d.RetryBackoff = time.Millisecond
+	d.MaxRetries = 0
+	return d
+}
+
+//checkDownloadedFile : Compare the output file with the content and check that no temporary file is left
+func checkDownloadedFile(t *testing.T, outFileName string, content []byte) {
+	t.Helper()
+	data, err := ioutil.ReadFile(outFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Errorf("%s: %d bytes are different from the content (%d bytes)", outFileName, len(data), len(content))
+	}
+	for _, suffix := range []string{PartialFileSuffix, PreallocatedFileSuffix, ManifestFileSuffix} {
+		if _, err := os.Stat(outFileName + suffix); err == nil {
+			t.Errorf("%s is not removed", outFileName+suffix)
+		}
+	}
+}
+
+func TestDownloadResume(t *testing.T) {
+	content := testGzipContent(t, 64*1024)
+	size := int64(len(content))
+	half := size / 2
+
+	tests := []struct {
+		name         string
+		partial      []byte
+		expectedSize int64
+		noRange      bool
+		wantRanges   []string
+	}{
+		//200: the whole file is downloaded
+		{name: "new", wantRanges: []string{""}},
+		//206: the download is resumed from the end of the partial file
+		{name: "resume", partial: content[:half], expectedSize: size, wantRanges: []string{fmt.Sprintf("bytes=%d-", half)}},
+		{name: "resume without size", partial: content[:half], wantRanges: []string{fmt.Sprintf("bytes=%d-", half)}},
+		//200 for the range request: the partial file is replaced by the whole file
+		{name: "range not supported", partial: content[:half], expectedSize: size, noRange: true, wantRanges: []string{fmt.Sprintf("bytes=%d-", half)}},
+		//416: the partial file already contains the whole file
+		{name: "range not satisfiable", partial: content, wantRanges: []string{fmt.Sprintf("bytes=%d-", size)}},
+		//No request: the size of the partial file is the expected size
+		{name: "already completed", partial: content, expectedSize: size},
+		//The partial file which is larger than the file is not used
+		{name: "partial too large", partial: append(append([]byte{}, content...), 'x'), expectedSize: size, wantRanges: []string{""}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestFileServer(t, content)
+			server.noRange = test.noRange
+			outFileName := filepath.Join(t.TempDir(), "output.csv.gz")
+			if test.partial != nil {
+				if err := ioutil.WriteFile(outFileName+PartialFileSuffix, test.partial, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := newTestDownloader(server).Download(server.URL, outFileName, test.expectedSize); err != nil {
+				t.Fatal(err)
+			}
+			checkDownloadedFile(t, outFileName, content)
+			if ranges := server.requests(); fmt.Sprint(ranges) != fmt.Sprint(test.wantRanges) {
+				t.Errorf("Range headers = %q, want %q", ranges, test.wantRanges)
+			}
+		})
+	}
+}
+
+func TestDownloadResumeAfterInterruption(t *testing.T) {
+	content := testGzipContent(t, 256*1024)
+	server := newTestFileServer(t, content)
+	outFileName := filepath.Join(t.TempDir(), "output.csv.gz")
+	downloader := newTestDownloader(server)
+
+	server.setCutAt(100 * 1024)
+	if err := downloader.Download(server.URL, outFileName, int64(len(content))); err == nil {
+		t.Fatal("the interrupted download returns no error")
+	}
+	fi, err := os.Stat(outFileName + PartialFileSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Size() == 0 || fi.Size() >= int64(len(content)) {
+		t.Fatalf("size of the partial file = %d", fi.Size())
+	}
+	server.requests()
+
+	server.setCutAt(0)
+	if err := downloader.Download(server.URL, outFileName, int64(len(content))); err != nil {
+		t.Fatal(err)
+	}
+	checkDownloadedFile(t, outFileName, content)
+	if ranges := server.requests(); len(ranges) != 1 || ranges[0] != fmt.Sprintf("bytes=%d-", fi.Size()) {
+		t.Errorf("Range headers = %q, want resume from %d", ranges, fi.Size())
+	}
+}
+
+func TestDownloadAfterInterruptedConcurrentDownload(t *testing.T) {
+	content := testGzipContent(t, 256*1024)
+	size := int64(len(content))
+	server := newTestFileServer(t, content)
+	outFileName := filepath.Join(t.TempDir(), "output.csv.gz")
+	downloader := newTestDownloader(server)
+
+	//The second part is interrupted so the preallocated file has the full size but the second half is zero
+	server.setCutAt(size/2 + 1024)
+	if err := downloader.ConcurrentDownload(server.URL, outFileName, 2, size); err == nil {
+		t.Fatal("the interrupted download returns no error")
+	}
+	if fi, err := os.Stat(outFileName + PreallocatedFileSuffix); err != nil || fi.Size() != size {
+		t.Fatalf("preallocated file: %v", err)
+	}
+
+	//The single-stream download must not treat the preallocated file as the completed file
+	server.setCutAt(0)
+	downloader.VerifyGzip = false
+	if err := downloader.Download(server.URL, outFileName, size); err != nil {
+		t.Fatal(err)
+	}
+	checkDownloadedFile(t, outFileName, content)
+}
+
+//holdWriter : http.ResponseWriter which waits for hold to be closed after the first remaining bytes are written
+type holdWriter struct {
+	http.ResponseWriter
+	remaining int64
+	hold      chan struct{}
+}
+
+func (w *holdWriter) Write(p []byte) (int, error) {
+	if w.remaining > 0 && int64(len(p)) > w.remaining {
+		n, err := w.ResponseWriter.Write(p[:w.remaining])
+		w.remaining = 0
+		if err != nil {
+			return n, err
+		}
+		w.ResponseWriter.(http.Flusher).Flush()
+		<-w.hold
+		m, err := w.ResponseWriter.Write(p[n:])
+		return n + m, err
+	}
+	w.remaining -= int64(len(p))
+	return w.ResponseWriter.Write(p)
+}
+
+func TestConcurrentDownloadCheckpointInPart(t *testing.T) {
+	content := testGzipContent(t, 256*1024)
+	size := int64(len(content))
+	hold := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(&holdWriter{ResponseWriter: w, remaining: 100 * 1024, hold: hold}, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+	outFileName := filepath.Join(t.TempDir(), "output.csv.gz")
+	downloader := NewDownloader(server.Client(), nil, false)
+	downloader.Progress = nil
+	downloader.CheckpointSize = 16 * 1024
+
+	result := make(chan error, 1)
+	go func() {
+		result <- downloader.ConcurrentDownload(server.URL, outFileName, 1, size)
+	}()
+
+	//The manifest must have the progress of the part while the part is still downloaded
+	var written int64
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if manifest := loadDownloadManifest(outFileName, 1, size); manifest != nil {
+			written = manifest.Parts[0].Written
+		}
+		if written >= 64*1024 {
+			break
+		}
+	}
+	close(hold)
+	if err := <-result; err != nil {
+		t.Fatal(err)
+	}
+	if written < 64*1024 || written > 100*1024 {
+		t.Errorf("checkpoint in the part = %d bytes, want 64KB - 100KB", written)
+	}
+	checkDownloadedFile(t, outFileName, content)
+}
+
+func TestDownloadRejectsUnexpectedLength(t *testing.T) {
+	content := testGzipContent(t, 64*1024)
+	size := int64(len(content))
+	half := size / 2
+
+	tests := []struct {
+		name         string
+		partial      []byte
+		expectedSize int64
+		handler      http.HandlerFunc
+	}{
+		//200: Content-Length is not the expected size
+		{name: "200 with another size", expectedSize: size + 10},
+		//206: Content-Range is for a file of another size
+		{name: "206 with another size", partial: content[:half], expectedSize: size + 10},
+		//206: the body is shorter than the remaining bytes of the file
+		{name: "206 with short body", partial: content[:half], expectedSize: size, handler: func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", half, half+99, size))
+			w.Header().Set("Content-Length", "100")
+			w.WriteHeader(206)
+			w.Write(content[half : half+100])
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestFileServer(t, content)
+			if test.handler != nil {
+				server.Config.Handler = test.handler
+			}
+			outFileName := filepath.Join(t.TempDir(), "output.csv.gz")
+			if test.partial != nil {
+				if err := ioutil.WriteFile(outFileName+PartialFileSuffix, test.partial, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := newTestDownloader(server).Download(server.URL, outFileName, test.expectedSize); err == nil {
+				t.Fatal("the response with an unexpected length returns no error")
+			}
+			if _, err := os.Stat(outFileName); err == nil {
+				t.Errorf("%s is created", outFileName)
+			}
+			//The partial file is not changed by the rejected response
+			data, _ := ioutil.ReadFile(outFileName + PartialFileSuffix)
+			if !bytes.Equal(data, test.partial) {
+				t.Errorf("partial file = %d bytes, want %d bytes", len(data), len(test.partial))
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package rthrest
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+//The identifier types used in InstrumentIdentifier.IdentifierType. PermId, Isin, Sedol and Cusip identify the entity which is extracted by ExtractByModeEntityEnum
+const (
+	IdentifierTypeRic    = "Ric"
+	IdentifierTypePermID = "PermId"
+	IdentifierTypeIsin   = "Isin"
+	IdentifierTypeSedol  = "Sedol"
+	IdentifierTypeCusip  = "Cusip"
+)
+
+//The columns added to each row by EntityAnnotator
+const (
+	EntityIDColumn  = "Entity ID"
+	SourceRICColumn = "Source RIC"
+)
+
+//NewEntityIdentifiers : Create the identifiers of the entities with the identifier type, e.g. IdentifierTypePermID
+func NewEntityIdentifiers(identifierType string, ids ...string) []InstrumentIdentifier {
+	identifiers := make([]InstrumentIdentifier, 0, len(ids))
+	for _, id := range ids {
+		identifiers = append(identifiers, InstrumentIdentifier{Identifier: id, IdentifierType: identifierType})
+	}
+	return identifiers
+}
+
+//UseExtractByEntity : Change the request to extract the data of the entities. All RICs of each entity over the query range are extracted.
+//DisplaySourceRIC is enabled so the RIC of each row can be mapped back to the entity by EntityAnnotator
+func UseExtractByEntity(request *TickHistoryMarketDepthExtractionRequest, identifiers []InstrumentIdentifier) {
+	request.IdentifierList.InstrumentIdentifiers = identifiers
+	request.Condition.ExtractBy = ExtractByModeEntityEnum
+	request.Condition.DisplaySourceRIC = true
+}
+
+//EntityRIC : The RIC of the entity from Start to End. Start and End are nil if they are not known
+type EntityRIC struct {
+	EntityID string
+	RIC      string
+	Start    *time.Time
+	End      *time.Time
+}
+
+//Contains : Return true if the time is in the period of the RIC. It returns true if the time is nil
+func (e *EntityRIC) Contains(t *time.Time) bool {
+	if t == nil {
+		return true
+	}
+	if e.Start != nil && t.Before(*e.Start) {
+		return false
+	}
+	if e.End != nil && t.After(*e.End) {
+		return false
+	}
+	return true
+}
+
+//GetEntityRICs : Return the RICs of each entity in the request over its query range.
+//Each entity is run in PreviewModeInstrumentEnum by PreviewInstruments so the RICs are mapped to the entity without the full extraction
+func (c *Client) GetEntityRICs(ctx context.Context, request *TickHistoryMarketDepthExtractionRequest) ([]EntityRIC, error) {
+	var mapping []EntityRIC
+	for _, identifier := range request.IdentifierList.InstrumentIdentifiers {
+		entityRequest := *request
+		entityRequest.IdentifierList.InstrumentIdentifiers = []InstrumentIdentifier{identifier}
+		entityRequest.Condition.ExtractBy = ExtractByModeEntityEnum
+
+		preview, err := c.PreviewInstruments(ctx, &entityRequest)
+		if err != nil {
+			return nil, fmt.Errorf("entity %s: %w", identifier.Identifier, err)
+		}
+		for _, entry := range preview.Instruments {
+			mapping = append(mapping, EntityRIC{
+				EntityID: identifier.Identifier,
+				RIC:      entry.RIC,
+				Start:    entry.Start,
+				End:      entry.End,
+			})
+		}
+	}
+	return mapping, nil
+}
+
+//EntityAnnotator : The post-processor of the CSV output of the extract-by-entity request.
+//It adds Entity ID and Source RIC columns to each row by using the RIC and Date-Time columns of the row
+type EntityAnnotator struct {
+	byRIC map[string][]EntityRIC
+}
+
+//NewEntityAnnotator : Create EntityAnnotator with the RICs of the entities returned by GetEntityRICs
+func NewEntityAnnotator(mapping []EntityRIC) *EntityAnnotator {
+	a := &EntityAnnotator{byRIC: make(map[string][]EntityRIC)}
+	for _, entry := range mapping {
+		ric := strings.ToUpper(entry.RIC)
+		a.byRIC[ric] = append(a.byRIC[ric], entry)
+	}
+	return a
+}
+
+//EntityID : Return the entity of the RIC at the time. It returns "" if the RIC doesn't belong to any entity at the time
+func (a *EntityAnnotator) EntityID(ric string, t *time.Time) string {
+	for _, entry := range a.byRIC[strings.ToUpper(ric)] {
+		if entry.Contains(t) {
+			return entry.EntityID
+		}
+	}
+	return ""
+}
+
+//Annotate : Read the decompressed CSV from r and write it to w with Entity ID and Source RIC columns at the end of each row.
+//The source RIC is taken from Source RIC column if it exists and only Entity ID column is added, otherwise it is taken from the first (#RIC) column
+func (a *EntityAnnotator) Annotate(r io.Reader, w io.Writer) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	writer := csv.NewWriter(w)
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	ricColumn, timeColumn := 0, -1
+	hasSourceRIC := false
+	for i, column := range header {
+		switch strings.TrimPrefix(column, "#") {
+		case SourceRICColumn:
+			ricColumn = i
+			hasSourceRIC = true
+		case "Date-Time":
+			timeColumn = i
+		}
+	}
+	if hasSourceRIC {
+		header = append(header, EntityIDColumn)
+	} else {
+		header = append(header, EntityIDColumn, SourceRICColumn)
+	}
+	if err = writer.Write(header); err != nil {
+		return err
+	}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		ric := ""
+		if ricColumn < len(row) {
+			ric = row[ricColumn]
+		}
+		var rowTime *time.Time
+		if timeColumn >= 0 && timeColumn < len(row) {
+			if t, err := time.Parse(time.RFC3339Nano, row[timeColumn]); err == nil {
+				rowTime = &t
+			}
+		}
+		row = append(row, a.EntityID(ric, rowTime))
+		if !hasSourceRIC {
+			row = append(row, ric)
+		}
+		if err = writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+//AnnotateFile : Annotate the CSV file and write the result to outFileName. The files whose names end with .gz are compressed by gzip
+func (a *EntityAnnotator) AnnotateFile(inFileName string, outFileName string) error {
+	in, err := os.Open(inFileName)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	var reader io.Reader = in
+	if strings.HasSuffix(inFileName, ".gz") {
+		gzipReader, err := gzip.NewReader(in)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	out, err := os.Create(outFileName)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if !strings.HasSuffix(outFileName, ".gz") {
+		if err = a.Annotate(reader, out); err != nil {
+			return err
+		}
+		return out.Close()
+	}
+	gzipWriter := gzip.NewWriter(out)
+	if err = a.Annotate(reader, gzipWriter); err != nil {
+		return err
+	}
+	if err = gzipWriter.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,59 @@
+package rthrest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+//testEntityAnnotator : The entity 4295905573 was VOD.L until 2017-08-21 and VODl.L after that
+func testEntityAnnotator(t *testing.T) *EntityAnnotator {
+	changed := testTime(t, "2017-08-21T23:59:59Z")
+	next := changed.Add(time.Second)
+	return NewEntityAnnotator([]EntityRIC{
+		{EntityID: "4295905573", RIC: "VOD.L", End: &changed},
+		{EntityID: "4295905573", RIC: "VODl.L", Start: &next},
+	})
+}
+
+func TestEntityAnnotatorAnnotate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name: "without Source RIC",
+			input: "#RIC,Date-Time,Type\n" +
+				"VOD.L,2017-08-21T09:00:00.000000000Z,Market Depth\n" +
+				"VODl.L,2017-08-22T09:00:00.000000000Z,Market Depth\n" +
+				"VOD.L,2017-08-22T09:00:00.000000000Z,Market Depth\n",
+			want: "#RIC,Date-Time,Type,Entity ID,Source RIC\n" +
+				"VOD.L,2017-08-21T09:00:00.000000000Z,Market Depth,4295905573,VOD.L\n" +
+				"VODl.L,2017-08-22T09:00:00.000000000Z,Market Depth,4295905573,VODl.L\n" +
+				"VOD.L,2017-08-22T09:00:00.000000000Z,Market Depth,,VOD.L\n",
+		},
+		{
+			name: "with Source RIC",
+			input: "#RIC,Date-Time,Source RIC\n" +
+				"4295905573,2017-08-21T09:00:00.000000000Z,VOD.L\n" +
+				"4295905573,2017-08-22T09:00:00.000000000Z,VODl.L\n",
+			want: "#RIC,Date-Time,Source RIC,Entity ID\n" +
+				"4295905573,2017-08-21T09:00:00.000000000Z,VOD.L,4295905573\n" +
+				"4295905573,2017-08-22T09:00:00.000000000Z,VODl.L,4295905573\n",
+		},
+		{name: "empty", input: "", want: ""},
+	}
+	annotator := testEntityAnnotator(t)
+	for _, test := range tests {
+		var output bytes.Buffer
+		if err := annotator.Annotate(strings.NewReader(test.input), &output); err != nil {
+			t.Errorf("%s: %s", test.name, err.Error())
+			continue
+		}
+		if output.String() != test.want {
+			t.Errorf("%s: Annotate =\n%s\nwant\n%s", test.name, output.String(), test.want)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package rthrest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+)
+
+//ExtractOptions : defined type for the options of Client.Extract.
+//MaxInstrumentDays sends the request to ExtractRaw without trying Extractions/ExtractWithNotes when EstimateInstrumentDays of the request is greater. It is not used if it is 0.
+//FallbackToRaw sends the request to ExtractRaw when Extractions/ExtractWithNotes rejects it because the result is too large.
+//PollInterval is the interval to check the status of the extraction. The default is 3 seconds
+type ExtractOptions struct {
+	MaxInstrumentDays int64
+	FallbackToRaw     bool
+	PollInterval      time.Duration
+}
+
+//ExtractResult : The result of Client.Extract. Rows contains the rows of Extractions/ExtractWithNotes.
+//If the request is sent to ExtractRaw, Rows is empty and Raw contains the JobID which is used to download the gzip file
+type ExtractResult struct {
+	Rows  []map[string]json.RawMessage
+	Notes []string
+	Raw   *RawExtractionResult
+}
+
+//IsRaw : Return true if the request is sent to ExtractRaw and the result must be downloaded
+func (r *ExtractResult) IsRaw() bool {
+	return r.Raw != nil
+}
+
+//ParseNotes : Parse the notes of the extraction. See ParseExtractionNotes
+func (r *ExtractResult) ParseNotes() *ExtractionNotes {
+	if r.Raw != nil {
+		return r.Raw.ParseNotes()
+	}
+	return ParseExtractionNotes(r.Notes)
+}
+
+//Maps : Return the rows as maps of the content field names to the values decoded by encoding/json, i.e. string, float64, bool or nil
+func (r *ExtractResult) Maps() ([]map[string]interface{}, error) {
+	rows := make([]map[string]interface{}, 0, len(r.Rows))
+	for _, row := range r.Rows {
+		values := make(map[string]interface{}, len(row))
+		for name, raw := range row {
+			var value interface{}
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, fmt.Errorf("field %q: %w", name, err)
+			}
+			values[name] = value
+		}
+		rows = append(rows, values)
+	}
+	return rows, nil
+}
+
+//Decode : Decode the rows to v which must be a pointer to a slice of structs, e.g. *[]DepthRow.
+//The field of the struct is matched with the content field name in the 'rth' tag, e.g. `rth:"Bid Price"`.
+//Without the tag, the field name is compared with the content field name without spaces, e.g. BidPrice and Bid Price, ignoring case
+func (r *ExtractResult) Decode(v interface{}) error {
+	slice := reflect.ValueOf(v)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice || slice.Elem().Type().Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Decode: %T is not a pointer to a slice of structs", v)
+	}
+	elemType := slice.Elem().Type().Elem()
+	fields := rowFieldIndex(elemType)
+
+	for _, row := range r.Rows {
+		elem := reflect.New(elemType).Elem()
+		for name, raw := range row {
+			index, ok := fields[normalizeFieldName(name)]
+			if !ok {
+				continue
+			}
+			if err := json.Unmarshal(raw, elem.Field(index).Addr().Interface()); err != nil {
+				return fmt.Errorf("field %q: %w", name, err)
+			}
+		}
+		slice.Elem().Set(reflect.Append(slice.Elem(), elem))
+	}
+	return nil
+}
+
+//rowFieldIndex : Map the normalized content field names to the indexes of the exported fields of the struct
+func rowFieldIndex(t reflect.Type) map[string]int {
+	fields := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Tag.Get("rth")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[normalizeFieldName(name)] = i
+	}
+	return fields
+}
+
+//normalizeFieldName : Remove spaces and change to lower case, e.g. Bid Price becomes bidprice
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.Replace(name, " ", "", -1))
+}
+
+//isResultTooLarge : Return true if the error is returned by Extractions/ExtractWithNotes because the request must be sent to ExtractRaw
+func isResultTooLarge(err error) bool {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	body := strings.ToLower(statusErr.Body)
+	return statusErr.StatusCode == 413 || (statusErr.StatusCode == 400 && (strings.Contains(body, "extractraw") || strings.Contains(body, "too large")))
+}
+
+//Extract : Send the request to Extractions/ExtractWithNotes and return the rows in memory. It is used by small requests, e.g. previews.
+//The request is sent to ExtractRaw instead if the result is too large. See ExtractOptions
+func (c *Client) Extract(ctx context.Context, request *TickHistoryMarketDepthExtractionRequest, options ExtractOptions) (*ExtractResult, error) {
+	if options.MaxInstrumentDays > 0 {
+		if estimate := EstimateInstrumentDays(request); estimate > options.MaxInstrumentDays {
+			log.Printf("Extract: %d instrument-days is more than %d, use ExtractRaw\n", estimate, options.MaxInstrumentDays)
+			return c.extractRaw(ctx, request, options.PollInterval)
+		}
+	}
+
+	result := &ExtractWithNotesResult{}
+	err := c.postAndWait(ctx, GetExtractWithNotesURL(c.URL), extractionRequestBody(request), result, options.PollInterval)
+	if err != nil && options.FallbackToRaw && isResultTooLarge(err) {
+		log.Printf("Extract: the result is too large, use ExtractRaw: %s\n", err.Error())
+		return c.extractRaw(ctx, request, options.PollInterval)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ExtractResult{Rows: result.Contents, Notes: result.Notes}, nil
+}
+
+//ExtractRaw : Send the request to Extractions/ExtractRaw and wait until the extraction is completed.
+//The result is downloaded from RawExtractionResults('jobId')/$value, e.g. by Stream or Downloader
+func (c *Client) ExtractRaw(ctx context.Context, request *TickHistoryMarketDepthExtractionRequest, pollInterval time.Duration) (*RawExtractionResult, error) {
+	result := &RawExtractionResult{}
+	if err := c.postAndWait(ctx, GetExtractRawURL(c.URL), extractionRequestBody(request), result, pollInterval); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+//extractRaw : Send the request to ExtractRaw and return it as ExtractResult
+func (c *Client) extractRaw(ctx context.Context, request *TickHistoryMarketDepthExtractionRequest, pollInterval time.Duration) (*ExtractResult, error) {
+	raw, err := c.ExtractRaw(ctx, request, pollInterval)
+	if err != nil {
+		return nil, err
+	}
+	return &ExtractResult{Notes: raw.Notes, Raw: raw}, nil
+}
+
+//extractionRequestBody : Wrap the request in ExtractionRequest object used by the extraction endpoints
+func extractionRequestBody(request interface{}) interface{} {
+	return struct {
+		ExtractionRequest interface{}
+	}{
+		ExtractionRequest: request,
+	}
+}
+
+//postAndWait : Send POST request with the JSON of in. While the response is 202 Accepted, the Location URL is checked every pollInterval.
+//The JSON of the completed response is decoded to out. The POST request and the polling are canceled when ctx is done
+func (c *Client) postAndWait(ctx context.Context, url string, in interface{}, out interface{}, pollInterval time.Duration) error {
+	if pollInterval <= 0 {
+		pollInterval = 3 * time.Second
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	resp, err := HTTPPostWithContext(ctx, c.HTTPClient, url, bytes.NewBuffer(data), c.Headers, c.Tracing)
+	if err != nil {
+		return err
+	}
+
+	for resp.StatusCode == 202 {
+		resp.Body.Close()
+		location := resp.Header.Get("Location")
+		//Change the protocol to https if it is http
+		if strings.HasPrefix(c.URL, "https:") {
+			location = strings.Replace(location, "http:", "https:", 1)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+		log.Printf("Checking Status of Extraction: %s\n", location)
+		resp, err = HTTPGetWithContext(ctx, c.HTTPClient, location, c.Headers, c.Tracing)
+		if err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,72 @@
+package rthrest
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestExtractRawPolling(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			w.Header().Set("Location", "http://"+r.Host+"/Extractions/ExtractRawResult(ExtractionId='0x1')")
+			w.WriteHeader(202)
+		default:
+			polls++
+			if polls < 3 {
+				w.Header().Set("Location", "http://"+r.Host+r.URL.Path)
+				w.WriteHeader(202)
+				return
+			}
+			w.Write([]byte(`{"JobId":"0x1","Notes":["Extraction ID: 2000000431584047"]}`))
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.Client(), server.URL+"/", false)
+
+	result, err := client.ExtractRaw(context.Background(), &TickHistoryMarketDepthExtractionRequest{}, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.JobID != "0x1" || polls != 3 {
+		t.Errorf("JobID = %q, polls = %d", result.JobID, polls)
+	}
+}
+
+func TestExtractRawCancelPost(t *testing.T) {
+	//The server doesn't respond to the POST request until the client cancels it
+	canceled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//The server detects the closed connection after the body is read
+		ioutil.ReadAll(r.Body)
+		select {
+		case <-r.Context().Done():
+			close(canceled)
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.Client(), server.URL+"/", false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.ExtractRaw(ctx, &TickHistoryMarketDepthExtractionRequest{}, time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("ExtractRaw returned after %s", elapsed)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Error("the POST request is not canceled")
+	}
+}
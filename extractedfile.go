@@ -0,0 +1,163 @@
+package rthrest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+)
+
+//GetExtractedFile : Get the information of the file produced by an extraction, e.g. by a scheduled extraction, from Extractions/ExtractedFiles('id')
+func (c *Client) GetExtractedFile(extractedFileID string) (*ExtractedFile, error) {
+	extractedFile := &ExtractedFile{}
+	if err := c.getJSON(GetExtractedFileURL(c.URL, extractedFileID), extractedFile); err != nil {
+		return nil, err
+	}
+	return extractedFile, nil
+}
+
+//DownloadExtractedFile : Download the contents of Extractions/ExtractedFiles('id')/$value to outFileName.
+//If outFileName is empty, ExtractedFileName is used.
+//numOfConn is the number of connections. If it is 0, the number of connections is chosen by AutoDownload.
+//The file is verified against ExtractedFile.Size
+func (c *Client) DownloadExtractedFile(extractedFile *ExtractedFile, outFileName string, numOfConn int) error {
+	return c.downloadExtractedFile(c.NewDownloader(), extractedFile, outFileName, numOfConn)
+}
+
+//downloadExtractedFile : Download the extracted file with the downloader. See DownloadExtractedFile
+func (c *Client) downloadExtractedFile(downloader *Downloader, extractedFile *ExtractedFile, outFileName string, numOfConn int) error {
+	if outFileName == "" {
+		outFileName = extractedFile.ExtractedFileName
+	}
+	url := GetExtractedFileGetDefaultStreamURL(c.URL, extractedFile.ExtractedFileId)
+
+	switch {
+	case numOfConn == 0:
+		return downloader.AutoDownload(url, outFileName)
+	case numOfConn > 1 && extractedFile.Size > 0:
+		return downloader.ConcurrentDownload(url, outFileName, numOfConn, extractedFile.Size)
+	default:
+		return downloader.Download(url, outFileName, extractedFile.Size)
+	}
+}
+
+//DownloadExtractedFileByID : Get the information of the extracted file and download it. See DownloadExtractedFile
+func (c *Client) DownloadExtractedFileByID(extractedFileID string, outFileName string, numOfConn int) (*ExtractedFile, error) {
+	extractedFile, err := c.GetExtractedFile(extractedFileID)
+	if err != nil {
+		return nil, err
+	}
+	return extractedFile, c.DownloadExtractedFile(extractedFile, outFileName, numOfConn)
+}
+
+//StreamExtractedFile : Return the contents of Extractions/ExtractedFiles('id')/$value as io.ReadCloser. See Stream
+func (c *Client) StreamExtractedFile(ctx context.Context, extractedFileID string, options StreamOptions) (io.ReadCloser, error) {
+	return c.streamURL(ctx, GetExtractedFileGetDefaultStreamURL(c.URL, extractedFileID), options)
+}
+
+//Type : Classify the file by FileType. It returns ExtractedFileTypeUnknownEnum if FileType is not known
+func (f *ExtractedFile) Type() ExtractedFileType {
+	var fileType ExtractedFileType
+	if err := fileType.UnmarshalText([]byte(f.FileType)); err != nil {
+		return ExtractedFileTypeUnknownEnum
+	}
+	return fileType
+}
+
+//IsNote : Return true if the file is the notes file or the RIC maintenance notes file of the extraction
+func (f *ExtractedFile) IsNote() bool {
+	fileType := f.Type()
+	return fileType == ExtractedFileTypeNoteEnum || fileType == ExtractedFileTypeRicMaintenanceNoteEnum
+}
+
+//ListReportExtractionFiles : List all files produced by the extraction, i.e. the data file, the notes file and the RIC maintenance notes file,
+//from Extractions/ReportExtractions('id')/Files. All pages are returned and the query can be nil
+func (c *Client) ListReportExtractionFiles(reportExtractionID string, query *ODataQuery) ([]ExtractedFile, error) {
+	var files []ExtractedFile
+	if err := c.getAllPages(GetReportExtractionFilesURL(c.URL, reportExtractionID), query, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+//FindExtractedFiles : Return the files of the type
+func FindExtractedFiles(files []ExtractedFile, fileType ExtractedFileType) []ExtractedFile {
+	var found []ExtractedFile
+	for _, file := range files {
+		if file.Type() == fileType {
+			found = append(found, file)
+		}
+	}
+	return found
+}
+
+//ExtractedFileOutputName : Return the name of the file written next to the data file.
+//For example, the notes file of output_123.csv.gz becomes output_123.notes.txt and the RIC maintenance notes file becomes output_123.ricmaintenance.txt.
+//The data file keeps dataFileName and the other files keep their ExtractedFileName in the directory of the data file
+func ExtractedFileOutputName(dataFileName string, extractedFile *ExtractedFile) string {
+	dir, base := filepath.Split(dataFileName)
+	if i := strings.Index(base, "."); i > 0 {
+		base = base[:i]
+	}
+	switch extractedFile.Type() {
+	case ExtractedFileTypeFullEnum:
+		return dataFileName
+	case ExtractedFileTypeNoteEnum:
+		return filepath.Join(dir, base+".notes.txt")
+	case ExtractedFileTypeRicMaintenanceNoteEnum:
+		return filepath.Join(dir, base+".ricmaintenance.txt")
+	default:
+		return filepath.Join(dir, extractedFile.ExtractedFileName)
+	}
+}
+
+//DownloadReportExtractionNotes : Download the notes files of the extraction next to the data file. See ExtractedFileOutputName.
+//It returns the names of the downloaded files
+func (c *Client) DownloadReportExtractionNotes(reportExtractionID string, dataFileName string) ([]string, error) {
+	files, err := c.ListReportExtractionFiles(reportExtractionID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.downloadNotes(files, dataFileName)
+}
+
+//downloadNotes : Download the notes files in the list with one connection
+func (c *Client) downloadNotes(files []ExtractedFile, dataFileName string) ([]string, error) {
+	var fileNames []string
+	for i := range files {
+		if !files[i].IsNote() {
+			continue
+		}
+		fileName := ExtractedFileOutputName(dataFileName, &files[i])
+		log.Printf("Download %s: %s to %s\n", files[i].FileType, files[i].ExtractedFileName, fileName)
+		if err := c.DownloadExtractedFile(&files[i], fileName, 1); err != nil {
+			return fileNames, err
+		}
+		fileNames = append(fileNames, fileName)
+	}
+	return fileNames, nil
+}
+
+//DownloadReportExtractionFiles : Download the data file of the extraction to outFileName and its notes files next to it.
+//If outFileName is empty, ExtractedFileName of the data file is used. numOfConn is used by the data file. See DownloadExtractedFile.
+//It returns all files of the extraction
+func (c *Client) DownloadReportExtractionFiles(reportExtractionID string, outFileName string, numOfConn int) ([]ExtractedFile, error) {
+	files, err := c.ListReportExtractionFiles(reportExtractionID, nil)
+	if err != nil {
+		return nil, err
+	}
+	dataFiles := FindExtractedFiles(files, ExtractedFileTypeFullEnum)
+	if len(dataFiles) == 0 {
+		return files, fmt.Errorf("report extraction %s has no data file", reportExtractionID)
+	}
+	if outFileName == "" {
+		outFileName = dataFiles[0].ExtractedFileName
+	}
+	if err := c.DownloadExtractedFile(&dataFiles[0], outFileName, numOfConn); err != nil {
+		return files, err
+	}
+	_, err = c.downloadNotes(files, outFileName)
+	return files, err
+}
@@ -1,73 +1,176 @@
-package rthrest
-
-import (
-	"encoding/json"
-	"reflect"
-)
-
-//MarshalText : JSON Marshaller for TickHistoryExtractByMode enumeration.
-//It uses tickHistoryExtractByMode string array variable to convert int (enum) to string
-func (d TickHistoryExtractByMode) MarshalText() ([]byte, error) {
-	return []byte(tickHistoryExtractByMode[d]), nil
-}
-
-//MarshalText : JSON Marshaller for PreviewMode enumeration.
-//It uses previewMode string array variable to convert int (enum) to string
-func (d PreviewMode) MarshalText() ([]byte, error) {
-	return []byte(previewMode[d]), nil
-}
-
-//MarshalText : JSON Marshaller for ReportDateRangeType enumeration.
-//It uses reportDateRangeType string array variable to convert int (enum) to string
-func (d ReportDateRangeType) MarshalText() ([]byte, error) {
-	return []byte(reportDateRangeType[d]), nil
-}
-
-//MarshalText : JSON Marshaller for TickHistoryMarketDepthViewOptions enumeration.
-//It uses tickHistoryMarketDepthViewOptions string array variable to convert int (enum) to string
-func (d TickHistoryMarketDepthViewOptions) MarshalText() ([]byte, error) {
-	return []byte(tickHistoryMarketDepthViewOptions[d]), nil
-}
-
-//MarshalText : JSON Marshaller for TickHistorySort enumeration.
-//It uses tickHistorySort string array variable to convert int (enum) to string
-func (d TickHistorySort) MarshalText() ([]byte, error) {
-	return []byte(tickHistorySort[d]), nil
-}
-
-//MarshalText : JSON Marshaller for TickHistoryTimeOptions enumeration.
-//It uses tickHistoryTimeOptions string array variable to convert int (enum) to string
-func (d TickHistoryTimeOptions) MarshalText() ([]byte, error) {
-	return []byte(tickHistoryTimeOptions[d]), nil
-}
-
-//MarshalJSON : The custom JSON Marshaller for InstrumentIdentifierList. It uses reflection to set the value for 'Metadata' field.
-//The default value is from 'odata" metadata
-func (r InstrumentIdentifierList) MarshalJSON() ([]byte, error) {
-	//This type is defined to avoid recursive while marshaling modified InstrumentIdentifierList
-	//The modified InstrumentIdentifierList will be copied to this type
-	//Therefore, json.Marshal can encode it to JSON with the value in 'Metatdata' field
-	type _InstrumentIdentifierList InstrumentIdentifierList
-	if r.Metadata == "" {
-		st := reflect.TypeOf(r)
-		field, _ := st.FieldByName("Metadata")
-		r.Metadata = field.Tag.Get("odata")
-	}
-	return json.Marshal(_InstrumentIdentifierList(r))
-
-}
-
-//MarshalJSON : The custom JSON Marshaller for TickHistoryMarketDepthExtractionRequest. It uses reflection to set the value for 'Metadata' field.
-//The default value is from 'odata" metadata
-func (r TickHistoryMarketDepthExtractionRequest) MarshalJSON() ([]byte, error) {
-	//This type is defined to avoid recursive while marshaling modified _TickHistoryMarketDepthExtractionRequest
-	//The modified _TickHistoryMarketDepthExtractionRequest will be copied to this type.
-	//Therefore, json.Marshal can encode it to JSON with the value in 'Metatdata' field
-	type _TickHistoryMarketDepthExtractionRequest TickHistoryMarketDepthExtractionRequest
-	if r.Metadata == "" {
-		st := reflect.TypeOf(r)
-		field, _ := st.FieldByName("Metadata")
-		r.Metadata = field.Tag.Get("odata")
-	}
-	return json.Marshal(_TickHistoryMarketDepthExtractionRequest(r))
-}
+package rthrest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+//MarshalText : JSON Marshaller for TickHistoryExtractByMode enumeration.
+//It uses tickHistoryExtractByMode string array variable to convert int (enum) to string
+func (d TickHistoryExtractByMode) MarshalText() ([]byte, error) {
+	return []byte(tickHistoryExtractByMode[d]), nil
+}
+
+//MarshalText : JSON Marshaller for PreviewMode enumeration.
+//It uses previewMode string array variable to convert int (enum) to string
+func (d PreviewMode) MarshalText() ([]byte, error) {
+	return []byte(previewMode[d]), nil
+}
+
+//MarshalText : JSON Marshaller for ReportDateRangeType enumeration.
+//It uses reportDateRangeType string array variable to convert int (enum) to string
+func (d ReportDateRangeType) MarshalText() ([]byte, error) {
+	return []byte(reportDateRangeType[d]), nil
+}
+
+//MarshalText : JSON Marshaller for TickHistoryMarketDepthViewOptions enumeration.
+//It uses tickHistoryMarketDepthViewOptions string array variable to convert int (enum) to string
+func (d TickHistoryMarketDepthViewOptions) MarshalText() ([]byte, error) {
+	return []byte(tickHistoryMarketDepthViewOptions[d]), nil
+}
+
+//MarshalText : JSON Marshaller for TickHistorySort enumeration.
+//It uses tickHistorySort string array variable to convert int (enum) to string
+func (d TickHistorySort) MarshalText() ([]byte, error) {
+	return []byte(tickHistorySort[d]), nil
+}
+
+//MarshalText : JSON Marshaller for TickHistoryTimeOptions enumeration.
+//It uses tickHistoryTimeOptions string array variable to convert int (enum) to string
+func (d TickHistoryTimeOptions) MarshalText() ([]byte, error) {
+	return []byte(tickHistoryTimeOptions[d]), nil
+}
+
+//unmarshalEnum : Convert the enumeration string to int (enum) by using the string array of the enumeration
+func unmarshalEnum(names []string, text []byte) (int, error) {
+	for i, name := range names {
+		if name == string(text) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown enumeration value: %q", string(text))
+}
+
+//UnmarshalText : JSON Unmarshaller for TickHistoryExtractByMode enumeration.
+//It uses tickHistoryExtractByMode string array variable to convert string to int (enum)
+func (d *TickHistoryExtractByMode) UnmarshalText(text []byte) error {
+	i, err := unmarshalEnum(tickHistoryExtractByMode[:], text)
+	*d = TickHistoryExtractByMode(i)
+	return err
+}
+
+//UnmarshalText : JSON Unmarshaller for PreviewMode enumeration.
+//It uses previewMode string array variable to convert string to int (enum)
+func (d *PreviewMode) UnmarshalText(text []byte) error {
+	i, err := unmarshalEnum(previewMode[:], text)
+	*d = PreviewMode(i)
+	return err
+}
+
+//UnmarshalText : JSON Unmarshaller for ReportDateRangeType enumeration.
+//It uses reportDateRangeType string array variable to convert string to int (enum)
+func (d *ReportDateRangeType) UnmarshalText(text []byte) error {
+	i, err := unmarshalEnum(reportDateRangeType[:], text)
+	*d = ReportDateRangeType(i)
+	return err
+}
+
+//UnmarshalText : JSON Unmarshaller for TickHistoryMarketDepthViewOptions enumeration.
+//It uses tickHistoryMarketDepthViewOptions string array variable to convert string to int (enum)
+func (d *TickHistoryMarketDepthViewOptions) UnmarshalText(text []byte) error {
+	i, err := unmarshalEnum(tickHistoryMarketDepthViewOptions[:], text)
+	*d = TickHistoryMarketDepthViewOptions(i)
+	return err
+}
+
+//UnmarshalText : JSON Unmarshaller for TickHistorySort enumeration.
+//It uses tickHistorySort string array variable to convert string to int (enum)
+func (d *TickHistorySort) UnmarshalText(text []byte) error {
+	i, err := unmarshalEnum(tickHistorySort[:], text)
+	*d = TickHistorySort(i)
+	return err
+}
+
+//UnmarshalText : JSON Unmarshaller for TickHistoryTimeOptions enumeration.
+//It uses tickHistoryTimeOptions string array variable to convert string to int (enum)
+func (d *TickHistoryTimeOptions) UnmarshalText(text []byte) error {
+	i, err := unmarshalEnum(tickHistoryTimeOptions[:], text)
+	*d = TickHistoryTimeOptions(i)
+	return err
+}
+
+//MarshalJSON : The custom JSON Marshaller for InstrumentIdentifierList. It uses reflection to set the value for 'Metadata' field.
+//The default value is from 'odata" metadata
+func (r InstrumentIdentifierList) MarshalJSON() ([]byte, error) {
+	//This type is defined to avoid recursive while marshaling modified InstrumentIdentifierList
+	//The modified InstrumentIdentifierList will be copied to this type
+	//Therefore, json.Marshal can encode it to JSON with the value in 'Metatdata' field
+	type _InstrumentIdentifierList InstrumentIdentifierList
+	if r.Metadata == "" {
+		st := reflect.TypeOf(r)
+		field, _ := st.FieldByName("Metadata")
+		r.Metadata = field.Tag.Get("odata")
+	}
+	return json.Marshal(_InstrumentIdentifierList(r))
+
+}
+
+//MarshalJSON : The custom JSON Marshaller for TickHistoryMarketDepthExtractionRequest. It uses reflection to set the value for 'Metadata' field.
+//The default value is from 'odata" metadata
+func (r TickHistoryMarketDepthExtractionRequest) MarshalJSON() ([]byte, error) {
+	//This type is defined to avoid recursive while marshaling modified _TickHistoryMarketDepthExtractionRequest
+	//The modified _TickHistoryMarketDepthExtractionRequest will be copied to this type.
+	//Therefore, json.Marshal can encode it to JSON with the value in 'Metatdata' field
+	type _TickHistoryMarketDepthExtractionRequest TickHistoryMarketDepthExtractionRequest
+	if r.Metadata == "" {
+		st := reflect.TypeOf(r)
+		field, _ := st.FieldByName("Metadata")
+		r.Metadata = field.Tag.Get("odata")
+	}
+	return json.Marshal(_TickHistoryMarketDepthExtractionRequest(r))
+}
+
+//MarshalJSON : The custom JSON Marshaller for TickHistoryMarketDepthReportTemplate. It uses reflection to set the value for 'Metadata' field.
+//The default value is from 'odata" metadata
+func (r TickHistoryMarketDepthReportTemplate) MarshalJSON() ([]byte, error) {
+	//This type is defined to avoid recursive while marshaling modified TickHistoryMarketDepthReportTemplate
+	//The modified TickHistoryMarketDepthReportTemplate will be copied to this type.
+	//Therefore, json.Marshal can encode it to JSON with the value in 'Metatdata' field
+	type _TickHistoryMarketDepthReportTemplate TickHistoryMarketDepthReportTemplate
+	if r.Metadata == "" {
+		st := reflect.TypeOf(r)
+		field, _ := st.FieldByName("Metadata")
+		r.Metadata = field.Tag.Get("odata")
+	}
+	return json.Marshal(_TickHistoryMarketDepthReportTemplate(r))
+}
+
+//MarshalJSON : The custom JSON Marshaller for InstrumentList. It uses reflection to set the value for 'Metadata' field.
+//The default value is from 'odata" metadata
+func (r InstrumentList) MarshalJSON() ([]byte, error) {
+	//This type is defined to avoid recursive while marshaling modified InstrumentList
+	//The modified InstrumentList will be copied to this type.
+	//Therefore, json.Marshal can encode it to JSON with the value in 'Metatdata' field
+	type _InstrumentList InstrumentList
+	if r.Metadata == "" {
+		st := reflect.TypeOf(r)
+		field, _ := st.FieldByName("Metadata")
+		r.Metadata = field.Tag.Get("odata")
+	}
+	return json.Marshal(_InstrumentList(r))
+}
+
+//MarshalText : JSON Marshaller for ExtractedFileType enumeration.
+//It uses extractedFileType string array variable to convert int (enum) to string
+func (d ExtractedFileType) MarshalText() ([]byte, error) {
+	return []byte(extractedFileType[d]), nil
+}
+
+//UnmarshalText : JSON Unmarshaller for ExtractedFileType enumeration.
+//It uses extractedFileType string array variable to convert string to int (enum)
+func (d *ExtractedFileType) UnmarshalText(text []byte) error {
+	i, err := unmarshalEnum(extractedFileType[:], text)
+	*d = ExtractedFileType(i)
+	return err
+}
@@ -0,0 +1,229 @@
+package rthrest
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//notesTimeLayout : The layout of the timestamps in the extraction notes, e.g. 06/06/2022 09:02:24
+const notesTimeLayout = "01/02/2006 15:04:05"
+
+var (
+	notesExtractionIDReg  = regexp.MustCompile(`^Extraction ID:\s*([0-9]+)`)
+	notesUserIDReg        = regexp.MustCompile(`^User ID:\s*(\S+)`)
+	notesScheduleReg      = regexp.MustCompile(`^Schedule:\s*(.*?)\s*\(ID = (\S+)\)`)
+	notesScheduleTimeReg  = regexp.MustCompile(`^Schedule Time:\s*(\d{2}/\d{2}/\d{4} \d{2}:\d{2}:\d{2})`)
+	notesStartedReg       = regexp.MustCompile(`^Processing started at\s*(\d{2}/\d{2}/\d{4} \d{2}:\d{2}:\d{2})`)
+	notesCompletedReg     = regexp.MustCompile(`^Processing completed (\w+) at\s*(\d{2}/\d{2}/\d{4} \d{2}:\d{2}:\d{2})`)
+	notesFinishedReg      = regexp.MustCompile(`^Extraction finished at\s*(\d{2}/\d{2}/\d{4} \d{2}:\d{2}:\d{2})( UTC)?.*?(?:\(([0-9.]+) secs\))?$`)
+	notesFileNameReg      = regexp.MustCompile(`[\w.\-]+\.(?:csv\.gz|txt\.gz|csv|txt|gz|zip|json)\b`)
+	notesQuotaReg         = regexp.MustCompile(`^Quota Message:\s*(.*)$`)
+	notesQuotaBeforeReg   = regexp.MustCompile(`Quota Count Before Extraction:\s*([0-9]+)`)
+	notesQuotaApprovedReg = regexp.MustCompile(`Instruments Approved for Extraction:\s*([0-9]+)`)
+	notesQuotaAfterReg    = regexp.MustCompile(`Quota Count After Extraction:\s*([0-9]+)(?:,\s*([0-9.]+)% of Limit)?`)
+	notesQuotaLimitReg    = regexp.MustCompile(`Quota Limit:\s*([0-9]+)`)
+	notesManifestReg      = regexp.MustCompile(`^Manifest:\s*(.*)$`)
+	notesRecordsReg       = regexp.MustCompile(`(?i)\b([0-9]+) (?:rows|records)\b`)
+	//The problems are matched at the start of the line or before ':' so the lines like "0 errors" or "completed without errors" are not reported
+	notesErrorReg   = regexp.MustCompile(`(?i)^(?:error|failed|failure)\b|(?:error|exception)\s*:|^processing completed unsuccessfully\b|\b(?:extraction|processing|request|job) failed\b`)
+	notesWarningReg = regexp.MustCompile(`(?i)^warning\b|\bwarning\s*:|\bembargo(?:ed)?\b|\bnot entitled\b|\bpermission\b|\bsuppressed\b`)
+)
+
+//QuotaNote : The values reported in the 'Quota Message' line of the extraction notes
+type QuotaNote struct {
+	Message             string
+	CountBefore         int64
+	InstrumentsApproved int64
+	CountAfter          int64
+	PercentOfLimit      float64
+	Limit               int64
+}
+
+//ManifestEntry : One instrument listed in the 'Manifest' lines of the extraction notes
+type ManifestEntry struct {
+	RIC    string
+	Domain string
+	Start  *time.Time
+	End    *time.Time
+	Status string
+	Count  int64
+}
+
+//ExtractionNotes : The information parsed from the Notes field of RawExtractionResult.
+//ScheduleTime, ProcessingStarted and ProcessingCompleted are the local times of the account as written in the notes, e.g. 06/06/2022 09:02:05.
+//The notes don't contain their time zone so they are kept as strings. ExtractionFinished is the only time tagged with UTC
+type ExtractionNotes struct {
+	ExtractionID        string
+	UserID              string
+	Schedule            string
+	ScheduleID          string
+	ScheduleTime        string
+	ProcessingStarted   string
+	ProcessingCompleted string
+	//ProcessingStatus is the word after 'Processing completed', e.g. successfully
+	ProcessingStatus   string
+	ExtractionFinished *time.Time
+	//ProcessingSeconds is the processing time reported by the servers in 'Extraction finished' line
+	ProcessingSeconds float64
+	FileNames         []string
+	//RecordCount is the total of the record counts in the manifest.
+	//If the notes have no manifest, it is the total of the row counts in the other lines, e.g. "8 rows"
+	RecordCount   int64
+	Manifest      []ManifestEntry
+	Quota         *QuotaNote
+	QuotaMessages []string
+	Warnings      []string
+	Errors        []string
+	//Lines contains all non-empty lines of the notes
+	Lines []string
+}
+
+//HasProblems : Return true if the notes contain any warning or error
+func (n *ExtractionNotes) HasProblems() bool {
+	return len(n.Warnings) > 0 || len(n.Errors) > 0
+}
+
+//ParseNotes : Parse the Notes field of RawExtractionResult to ExtractionNotes
+func (r *RawExtractionResult) ParseNotes() *ExtractionNotes {
+	return ParseExtractionNotes(r.Notes)
+}
+
+//ParseExtractionNotes : Parse the extraction notes line by line. The notes can be a multi-line string or a list of strings.
+//Unknown lines are kept in Lines so they can still be logged
+func ParseExtractionNotes(notes []string) *ExtractionNotes {
+	result := &ExtractionNotes{}
+	var manifestHeader []string
+	var summaryCount int64
+
+	for _, note := range notes {
+		for _, line := range strings.Split(note, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			result.Lines = append(result.Lines, line)
+
+			if m := notesExtractionIDReg.FindStringSubmatch(line); m != nil {
+				result.ExtractionID = m[1]
+			} else if m := notesUserIDReg.FindStringSubmatch(line); m != nil {
+				result.UserID = m[1]
+			} else if m := notesScheduleReg.FindStringSubmatch(line); m != nil {
+				result.Schedule = m[1]
+				result.ScheduleID = m[2]
+			} else if m := notesScheduleTimeReg.FindStringSubmatch(line); m != nil {
+				result.ScheduleTime = m[1]
+			} else if m := notesStartedReg.FindStringSubmatch(line); m != nil {
+				result.ProcessingStarted = m[1]
+			} else if m := notesCompletedReg.FindStringSubmatch(line); m != nil {
+				result.ProcessingStatus = m[1]
+				result.ProcessingCompleted = m[2]
+			} else if m := notesFinishedReg.FindStringSubmatch(line); m != nil {
+				if m[2] != "" {
+					result.ExtractionFinished = parseNotesTime(m[1])
+				}
+				result.ProcessingSeconds, _ = strconv.ParseFloat(m[3], 64)
+			} else if m := notesQuotaReg.FindStringSubmatch(line); m != nil {
+				result.QuotaMessages = append(result.QuotaMessages, m[1])
+				result.Quota = parseQuotaNote(m[1])
+			} else if m := notesManifestReg.FindStringSubmatch(line); m != nil {
+				if strings.HasPrefix(m[1], "#") {
+					manifestHeader = strings.Split(strings.TrimPrefix(m[1], "#"), ",")
+				} else if entry := parseManifestEntry(manifestHeader, m[1]); entry != nil {
+					result.Manifest = append(result.Manifest, *entry)
+					result.RecordCount += entry.Count
+				}
+				continue
+			} else if m := notesRecordsReg.FindStringSubmatch(line); m != nil {
+				count, _ := strconv.ParseInt(m[1], 10, 64)
+				summaryCount += count
+			}
+
+			for _, name := range notesFileNameReg.FindAllString(line, -1) {
+				result.FileNames = append(result.FileNames, name)
+			}
+
+			switch {
+			case notesErrorReg.MatchString(line):
+				result.Errors = append(result.Errors, line)
+			case notesWarningReg.MatchString(line):
+				result.Warnings = append(result.Warnings, line)
+			}
+		}
+	}
+	if len(result.Manifest) == 0 {
+		result.RecordCount = summaryCount
+	}
+	return result
+}
+
+//parseNotesTime : Parse the timestamp tagged with UTC in the notes. It returns nil if the timestamp is invalid
+func parseNotesTime(value string) *time.Time {
+	t, err := time.Parse(notesTimeLayout, value)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+//parseQuotaNote : Parse the numbers in the 'Quota Message' line
+func parseQuotaNote(message string) *QuotaNote {
+	quota := &QuotaNote{Message: message}
+	if m := notesQuotaBeforeReg.FindStringSubmatch(message); m != nil {
+		quota.CountBefore, _ = strconv.ParseInt(m[1], 10, 64)
+	}
+	if m := notesQuotaApprovedReg.FindStringSubmatch(message); m != nil {
+		quota.InstrumentsApproved, _ = strconv.ParseInt(m[1], 10, 64)
+	}
+	if m := notesQuotaAfterReg.FindStringSubmatch(message); m != nil {
+		quota.CountAfter, _ = strconv.ParseInt(m[1], 10, 64)
+		quota.PercentOfLimit, _ = strconv.ParseFloat(m[2], 64)
+	}
+	if m := notesQuotaLimitReg.FindStringSubmatch(message); m != nil {
+		quota.Limit, _ = strconv.ParseInt(m[1], 10, 64)
+	}
+	return quota
+}
+
+//parseManifestEntry : Parse a 'Manifest' line by using the column names in the manifest header (#RIC,Domain,Start,End,Status,Count)
+func parseManifestEntry(header []string, line string) *ManifestEntry {
+	return manifestEntryFromRecord(header, strings.Split(line, ","))
+}
+
+//manifestEntryFromRecord : Convert the values of a manifest line or a CSV record to ManifestEntry by using the column names in the header.
+//If the header is nil, the columns of the manifest (RIC,Domain,Start,End,Status,Count) are used
+func manifestEntryFromRecord(header []string, values []string) *ManifestEntry {
+	if header == nil {
+		header = []string{"RIC", "Domain", "Start", "End", "Status", "Count"}
+	}
+	entry := &ManifestEntry{}
+	for i, column := range header {
+		if i >= len(values) {
+			break
+		}
+		value := strings.TrimSpace(values[i])
+		switch strings.TrimSpace(column) {
+		case "RIC":
+			entry.RIC = value
+		case "Domain":
+			entry.Domain = value
+		case "Start":
+			if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				entry.Start = &t
+			}
+		case "End":
+			if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				entry.End = &t
+			}
+		case "Status":
+			entry.Status = value
+		case "Count":
+			entry.Count, _ = strconv.ParseInt(value, 10, 64)
+		}
+	}
+	if entry.RIC == "" {
+		return nil
+	}
+	return entry
+}
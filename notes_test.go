@@ -0,0 +1,154 @@
+package rthrest
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+//notesWithManifest : The notes of a TickHistoryMarketDepthExtractionRequest returned by ExtractRaw
+const notesWithManifest = `Extraction Services Version 16.0.43633 (806c08a4ae8f), Built May  9 2022 17:21:14
+User ID: 9008895
+Extraction ID: 2000000431584047
+Correlation ID: CiD/9008895/AAAAAA.081a5b6c2ed2fc5f/RA/EXT.2000000431584047
+Schedule: 0x081a5b6c2f020e87 (ID = 0x0000000000000000)
+Input List (2 items):  (ID = 0x081a5b6c2f020e87) Created: 06/06/2022 09:02:14 Last Modified: 06/06/2022 09:02:14
+Report Template: _OnD_0x081a5b6c2f020e87 (ID = 0x081a5b6c4f420e87) Created: 06/06/2022 09:01:58 Last Modified: 06/06/2022 09:01:58
+Schedule dispatched via message queue (0x081a5b6c2f020e87)
+Schedule Time: 06/06/2022 09:01:59
+Processing started at 06/06/2022 09:02:05
+Processing completed successfully at 06/06/2022 09:02:25
+Extraction finished at 06/06/2022 08:02:25 UTC, with servers: tm04n03, TRTH (9.156 secs)
+Instrument <RIC,IBM.N> expanded to 1 RIC: IBM.N.
+Instrument <RIC,MSFT.O> expanded to 1 RIC: MSFT.O.
+Total instruments after instrument expansion = 2
+
+Range Query from 2022-05-01T00:00:00.000 to 2022-05-02T00:00:00.000 (UTC)
+Wrote 15 rows to _OnD_0x081a5b6c2f020e87.csv.gz
+Quota Message: INFO: Tick History Cash Quota Count Before Extraction: 3102; Instruments Approved for Extraction: 2; Quota Count After Extraction: 3104, 620.8% of Limit; Quota Limit: 500
+Manifest: #RIC,Domain,Start,End,Status,Count
+Manifest: IBM.N,Market Price,2022-05-01T23:00:03.094264664Z,2022-05-01T23:57:10.474385281Z,Active,8
+Manifest: MSFT.O,Market Price,2022-05-01T23:00:01.000000000Z,2022-05-01T23:59:59.000000000Z,Active,7`
+
+//notesWithProblems : The notes of an extraction which has problems
+const notesWithProblems = `User ID: 9008895
+Extraction ID: 2000000431584048
+Processing started at 06/06/2022 10:00:00
+Processing completed unsuccessfully at 06/06/2022 10:00:10
+Error: The RIC 'BAD.X' is not valid
+Warning: Data for VOD.L is embargoed until 06/06/2022 10:15:00
+You are not entitled to the instrument TEST.L
+Extraction failed for 1 instrument
+Wrote 12 records, 0 errors
+Validation completed without errors`
+
+func TestParseExtractionNotes(t *testing.T) {
+	notes := ParseExtractionNotes([]string{notesWithManifest})
+
+	if notes.ExtractionID != "2000000431584047" || notes.UserID != "9008895" {
+		t.Errorf("ExtractionID, UserID = %q, %q", notes.ExtractionID, notes.UserID)
+	}
+	if notes.Schedule != "0x081a5b6c2f020e87" || notes.ScheduleID != "0x0000000000000000" {
+		t.Errorf("Schedule, ScheduleID = %q, %q", notes.Schedule, notes.ScheduleID)
+	}
+	if notes.ProcessingStatus != "successfully" {
+		t.Errorf("ProcessingStatus = %q", notes.ProcessingStatus)
+	}
+	//The processing times are local times, 09:02 local is 08:02 UTC in 'Extraction finished' line
+	if notes.ScheduleTime != "06/06/2022 09:01:59" || notes.ProcessingStarted != "06/06/2022 09:02:05" || notes.ProcessingCompleted != "06/06/2022 09:02:25" {
+		t.Errorf("ScheduleTime, ProcessingStarted, ProcessingCompleted = %q, %q, %q", notes.ScheduleTime, notes.ProcessingStarted, notes.ProcessingCompleted)
+	}
+	wantFinished := time.Date(2022, 6, 6, 8, 2, 25, 0, time.UTC)
+	if notes.ExtractionFinished == nil || !notes.ExtractionFinished.Equal(wantFinished) || notes.ProcessingSeconds != 9.156 {
+		t.Errorf("ExtractionFinished, ProcessingSeconds = %v, %v, want %v", notes.ExtractionFinished, notes.ProcessingSeconds, wantFinished)
+	}
+	if len(notes.FileNames) != 1 || notes.FileNames[0] != "_OnD_0x081a5b6c2f020e87.csv.gz" {
+		t.Errorf("FileNames = %v", notes.FileNames)
+	}
+
+	if len(notes.Manifest) != 2 {
+		t.Fatalf("len(Manifest) = %d, want 2", len(notes.Manifest))
+	}
+	if entry := notes.Manifest[0]; entry.RIC != "IBM.N" || entry.Domain != "Market Price" || entry.Status != "Active" || entry.Count != 8 || entry.Start == nil || entry.End == nil {
+		t.Errorf("Manifest[0] = %+v", entry)
+	}
+	//The manifest is used and the "Wrote 15 rows" line is not added again
+	if notes.RecordCount != 15 {
+		t.Errorf("RecordCount = %d, want 15", notes.RecordCount)
+	}
+
+	if notes.Quota == nil {
+		t.Fatal("Quota is nil")
+	}
+	if q := notes.Quota; q.CountBefore != 3102 || q.InstrumentsApproved != 2 || q.CountAfter != 3104 || q.PercentOfLimit != 620.8 || q.Limit != 500 {
+		t.Errorf("Quota = %+v", q)
+	}
+	if notes.HasProblems() {
+		t.Errorf("HasProblems = true, Warnings: %v, Errors: %v", notes.Warnings, notes.Errors)
+	}
+}
+
+func TestParseExtractionNotesProblems(t *testing.T) {
+	notes := ParseExtractionNotes(strings.Split(notesWithProblems, "\n"))
+
+	wantErrors := []string{
+		"Processing completed unsuccessfully at 06/06/2022 10:00:10",
+		"Error: The RIC 'BAD.X' is not valid",
+		"Extraction failed for 1 instrument",
+	}
+	wantWarnings := []string{
+		"Warning: Data for VOD.L is embargoed until 06/06/2022 10:15:00",
+		"You are not entitled to the instrument TEST.L",
+	}
+	if strings.Join(notes.Errors, "|") != strings.Join(wantErrors, "|") {
+		t.Errorf("Errors = %q, want %q", notes.Errors, wantErrors)
+	}
+	if strings.Join(notes.Warnings, "|") != strings.Join(wantWarnings, "|") {
+		t.Errorf("Warnings = %q, want %q", notes.Warnings, wantWarnings)
+	}
+	if notes.ProcessingStatus != "unsuccessfully" {
+		t.Errorf("ProcessingStatus = %q", notes.ProcessingStatus)
+	}
+	//Without the manifest, the row counts of the other lines are used
+	if notes.RecordCount != 12 {
+		t.Errorf("RecordCount = %d, want 12", notes.RecordCount)
+	}
+}
+
+func TestParseExtractionNotesFinishedWithoutUTC(t *testing.T) {
+	//The time without UTC is a local time, so only the processing seconds are kept
+	notes := ParseExtractionNotes([]string{"Extraction finished at 06/06/2022 09:02:25, with servers: tm04n03 (9.156 secs)"})
+	if notes.ExtractionFinished != nil || notes.ProcessingSeconds != 9.156 {
+		t.Errorf("ExtractionFinished, ProcessingSeconds = %v, %v, want nil, 9.156", notes.ExtractionFinished, notes.ProcessingSeconds)
+	}
+}
+
+func TestParseExtractionNotesClassifier(t *testing.T) {
+	tests := []struct {
+		line    string
+		isError bool
+		warning bool
+	}{
+		{"ERROR: Identifier validation failed", true, false},
+		{"Failed to write the file", true, false},
+		{"Request failed with status 500", true, false},
+		{"System.TimeoutException: The operation has timed out", true, false},
+		{"Exception: The operation has timed out", true, false},
+		{"0 errors", false, false},
+		{"Processing completed without errors", false, false},
+		{"Report contains no failed instruments", false, false},
+		{"WARNING: The date range is truncated", false, true},
+		{"0 warnings", false, false},
+		{"Instrument VOD.L is suppressed", false, true},
+		{"User has no permission for the venue", false, true},
+	}
+	for _, test := range tests {
+		notes := ParseExtractionNotes([]string{test.line})
+		if got := len(notes.Errors) == 1; got != test.isError {
+			t.Errorf("%q: error = %v, want %v", test.line, got, test.isError)
+		}
+		if got := len(notes.Warnings) == 1; got != test.warning {
+			t.Errorf("%q: warning = %v, want %v", test.line, got, test.warning)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package rthrest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+//ODataQuery : defined type for the OData query options of the listing endpoints. The zero value doesn't add any option.
+//Filter is the expression of $filter, e.g. Status eq 'Completed'. Select and OrderBy are joined with comma.
+//Top limits the number of entities. It is not used if it is 0
+type ODataQuery struct {
+	Filter  string
+	Select  []string
+	OrderBy []string
+	Top     int
+}
+
+//NewODataQuery : Create an empty ODataQuery. The options are added by the With methods
+func NewODataQuery() *ODataQuery {
+	return &ODataQuery{}
+}
+
+//WithFilter : Set $filter. If $filter is already set, the expressions are combined with 'and'
+func (q *ODataQuery) WithFilter(filter string) *ODataQuery {
+	if q.Filter == "" {
+		q.Filter = filter
+	} else {
+		q.Filter = "(" + q.Filter + ") and (" + filter + ")"
+	}
+	return q
+}
+
+//WithSelect : Add the properties to $select
+func (q *ODataQuery) WithSelect(properties ...string) *ODataQuery {
+	q.Select = append(q.Select, properties...)
+	return q
+}
+
+//WithOrderBy : Add the property to $orderby. If descending is true, 'desc' is added after the property
+func (q *ODataQuery) WithOrderBy(property string, descending bool) *ODataQuery {
+	if descending {
+		property += " desc"
+	}
+	q.OrderBy = append(q.OrderBy, property)
+	return q
+}
+
+//WithTop : Set $top
+func (q *ODataQuery) WithTop(top int) *ODataQuery {
+	q.Top = top
+	return q
+}
+
+//Encode : Return the query string of the options, e.g. $filter=Status%20eq%20%27Completed%27&$top=10.
+//It returns "" if the query is nil or empty
+func (q *ODataQuery) Encode() string {
+	if q == nil {
+		return ""
+	}
+	var options []string
+	if q.Filter != "" {
+		options = append(options, "$filter="+escapeODataValue(q.Filter))
+	}
+	if len(q.Select) > 0 {
+		options = append(options, "$select="+escapeODataValue(strings.Join(q.Select, ",")))
+	}
+	if len(q.OrderBy) > 0 {
+		options = append(options, "$orderby="+escapeODataValue(strings.Join(q.OrderBy, ",")))
+	}
+	if q.Top > 0 {
+		options = append(options, "$top="+strconv.Itoa(q.Top))
+	}
+	return strings.Join(options, "&")
+}
+
+//Apply : Add the query string of the options to the URL
+func (q *ODataQuery) Apply(rawURL string) string {
+	query := q.Encode()
+	if query == "" {
+		return rawURL
+	}
+	if strings.Contains(rawURL, "?") {
+		return rawURL + "&" + query
+	}
+	return rawURL + "?" + query
+}
+
+//escapeODataValue : Escape the value of the query option. Spaces are encoded as %20 instead of +
+func escapeODataValue(value string) string {
+	return strings.Replace(url.QueryEscape(value), "+", "%20", -1)
+}
+
+//ODataString : Return the string literal used in $filter, e.g. 'O''Neil'
+func ODataString(value string) string {
+	return "'" + strings.Replace(value, "'", "''", -1) + "'"
+}
+
+//PageIterator : Iterator over the pages of the OData collection. The next page is requested from @odata.nextlink of the current page.
+//
+//	it := client.NewPageIterator(url, query)
+//	for it.Next() {
+//		var page []ReportExtraction
+//		if err := it.Decode(&page); err != nil { ... }
+//	}
+//	if err := it.Err(); err != nil { ... }
+type PageIterator struct {
+	client *Client
+	next   string
+	page   *ODataCollection
+	err    error
+}
+
+//NewPageIterator : Create the iterator over the collection of the URL. The query can be nil
+func (c *Client) NewPageIterator(rawURL string, query *ODataQuery) *PageIterator {
+	return &PageIterator{client: c, next: query.Apply(rawURL)}
+}
+
+//Next : Request the next page. It returns false when there are no more pages or the request fails. See Err
+func (it *PageIterator) Next() bool {
+	if it.err != nil || it.next == "" {
+		return false
+	}
+	page := &ODataCollection{}
+	if err := it.client.getJSON(it.next, page); err != nil {
+		it.err = err
+		return false
+	}
+	it.page = page
+	it.next = page.NextLink
+	return true
+}
+
+//Decode : Decode the value array of the current page to v, e.g. *[]ReportExtraction
+func (it *PageIterator) Decode(v interface{}) error {
+	if it.page == nil {
+		return fmt.Errorf("no page, Next must be called first")
+	}
+	if len(it.page.Value) == 0 {
+		return nil
+	}
+	return json.Unmarshal(it.page.Value, v)
+}
+
+//Err : Return the error which stopped the iterator
+func (it *PageIterator) Err() error {
+	return it.err
+}
+
+//getAllPages : Decode the value arrays of all pages of the collection and append them to v. v must be a pointer to a slice, e.g. *[]Schedule
+func (c *Client) getAllPages(rawURL string, query *ODataQuery, v interface{}) error {
+	slice := reflect.ValueOf(v)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("getAllPages: %T is not a pointer to a slice", v)
+	}
+	it := c.NewPageIterator(rawURL, query)
+	for it.Next() {
+		page := reflect.New(slice.Elem().Type())
+		if err := it.Decode(page.Interface()); err != nil {
+			return err
+		}
+		slice.Elem().Set(reflect.AppendSlice(slice.Elem(), page.Elem()))
+	}
+	return it.Err()
+}
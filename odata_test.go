@@ -0,0 +1,181 @@
+package rthrest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestODataQueryEncode(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *ODataQuery
+		want  string
+	}{
+		{name: "nil", query: nil, want: ""},
+		{name: "empty", query: NewODataQuery(), want: ""},
+		{name: "filter", query: NewODataQuery().WithFilter("Status eq 'Completed'"), want: "$filter=Status%20eq%20%27Completed%27"},
+		{
+			name:  "combined filter",
+			query: NewODataQuery().WithFilter("Status eq 'Completed'").WithFilter("ExtractionDateUtc gt 2022-06-01T00:00:00Z"),
+			want:  "$filter=%28Status%20eq%20%27Completed%27%29%20and%20%28ExtractionDateUtc%20gt%202022-06-01T00%3A00%3A00Z%29",
+		},
+		{name: "select", query: NewODataQuery().WithSelect("JobId", "Notes"), want: "$select=JobId%2CNotes"},
+		{name: "order by", query: NewODataQuery().WithOrderBy("ExtractionDateUtc", true).WithOrderBy("ScheduleName", false), want: "$orderby=ExtractionDateUtc%20desc%2CScheduleName"},
+		{name: "top", query: NewODataQuery().WithTop(10), want: "$top=10"},
+		{name: "zero top", query: NewODataQuery().WithTop(0), want: ""},
+		{
+			name:  "all options",
+			query: NewODataQuery().WithFilter("Name eq "+ODataString("O'Neil")).WithSelect("ListId").WithOrderBy("Name", false).WithTop(5),
+			want:  "$filter=Name%20eq%20%27O%27%27Neil%27&$select=ListId&$orderby=Name&$top=5",
+		},
+	}
+	for _, test := range tests {
+		if got := test.query.Encode(); got != test.want {
+			t.Errorf("%s: Encode() = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestODataQueryApply(t *testing.T) {
+	query := NewODataQuery().WithTop(10)
+	tests := []struct {
+		query *ODataQuery
+		url   string
+		want  string
+	}{
+		{query: nil, url: "https://host/Extractions/Schedules", want: "https://host/Extractions/Schedules"},
+		{query: query, url: "https://host/Extractions/Schedules", want: "https://host/Extractions/Schedules?$top=10"},
+		{query: query, url: "https://host/StandardExtractions/UserPackageDeliveryGetUserPackageDeliveriesByDateRange(SubscriptionId='0x1')?$skiptoken=2", want: "https://host/StandardExtractions/UserPackageDeliveryGetUserPackageDeliveriesByDateRange(SubscriptionId='0x1')?$skiptoken=2&$top=10"},
+	}
+	for _, test := range tests {
+		if got := test.query.Apply(test.url); got != test.want {
+			t.Errorf("Apply(%q) = %q, want %q", test.url, got, test.want)
+		}
+	}
+}
+
+func TestODataString(t *testing.T) {
+	tests := map[string]string{
+		"":        "''",
+		"IBM.N":   "'IBM.N'",
+		"O'Neil":  "'O''Neil'",
+		"''":      "''''''",
+		"a'b'c'd": "'a''b''c''d'",
+	}
+	for value, want := range tests {
+		if got := ODataString(value); got != want {
+			t.Errorf("ODataString(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+//testPageServer : The server of Extractions/ReportExtractions which returns the pages linked by @odata.nextlink.
+//The page of failPage returns HTTP 500. The query strings of the requests are recorded
+type testPageServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	queries  []string
+	failPage int
+}
+
+func newTestPageServer(t *testing.T, pages ...[]ReportExtraction) *testPageServer {
+	s := &testPageServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.queries = append(s.queries, r.URL.RawQuery)
+		page := 0
+		if token := r.URL.Query().Get("$skiptoken"); token != "" {
+			page = int(token[0] - '0')
+		}
+		if page == s.failPage {
+			http.Error(w, "Internal Server Error", 500)
+			return
+		}
+		collection := map[string]interface{}{"value": pages[page]}
+		if page+1 < len(pages) {
+			collection["@odata.nextlink"] = s.URL + "/Extractions/ReportExtractions?$skiptoken=" + string(rune('0'+page+1))
+		}
+		json.NewEncoder(w).Encode(collection)
+	}))
+	s.failPage = -1
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestGetAllPages(t *testing.T) {
+	server := newTestPageServer(t,
+		[]ReportExtraction{{ReportExtractionId: "1"}, {ReportExtractionId: "2"}},
+		[]ReportExtraction{{ReportExtractionId: "3"}},
+		[]ReportExtraction{{ReportExtractionId: "4"}, {ReportExtractionId: "5"}},
+	)
+	client := NewClient(server.Client(), server.URL+"/", false)
+
+	extractions, err := client.ListReportExtractions(NewODataQuery().WithFilter("Status eq 'Completed'").WithTop(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, extraction := range extractions {
+		ids = append(ids, extraction.ReportExtractionId)
+	}
+	if strings.Join(ids, ",") != "1,2,3,4,5" {
+		t.Errorf("ReportExtractionIds = %v, want 1 - 5", ids)
+	}
+	//The query is applied to the first URL only. The next pages are requested by @odata.nextlink as it is
+	want := []string{"$filter=Status%20eq%20%27Completed%27&$top=5", "$skiptoken=1", "$skiptoken=2"}
+	if strings.Join(server.queries, "|") != strings.Join(want, "|") {
+		t.Errorf("queries = %q, want %q", server.queries, want)
+	}
+}
+
+func TestGetAllPagesError(t *testing.T) {
+	server := newTestPageServer(t,
+		[]ReportExtraction{{ReportExtractionId: "1"}},
+		[]ReportExtraction{{ReportExtractionId: "2"}},
+		[]ReportExtraction{{ReportExtractionId: "3"}},
+	)
+	server.failPage = 1
+	client := NewClient(server.Client(), server.URL+"/", false)
+
+	//The error of the second page stops the iterator and is returned by Err
+	it := client.NewPageIterator(GetReportExtractionsURL(client.URL), nil)
+	var page []ReportExtraction
+	if err := it.Decode(&page); err == nil {
+		t.Error("Decode before Next: want error")
+	}
+	if !it.Next() {
+		t.Fatalf("Next of the first page = false, Err = %v", it.Err())
+	}
+	if err := it.Decode(&page); err != nil || len(page) != 1 || page[0].ReportExtractionId != "1" {
+		t.Errorf("first page = %+v, %v", page, err)
+	}
+	if it.Next() {
+		t.Error("Next of the failed page = true")
+	}
+	if statusErr, ok := it.Err().(*HTTPStatusError); !ok || statusErr.StatusCode != 500 {
+		t.Errorf("Err = %v, want HTTP 500", it.Err())
+	}
+	if it.Next() {
+		t.Error("Next after the error = true")
+	}
+
+	if extractions, err := client.ListReportExtractions(nil); err == nil || extractions != nil {
+		t.Errorf("ListReportExtractions = %+v, %v, want the error of the second page", extractions, err)
+	}
+	if len(server.queries) != 4 || server.queries[2] != "" {
+		t.Errorf("queries = %q", server.queries)
+	}
+}
+
+func TestGetAllPagesType(t *testing.T) {
+	client := NewClient(http.DefaultClient, "http://localhost/", false)
+	var extractions []ReportExtraction
+	if err := client.getAllPages("http://localhost/Extractions/ReportExtractions", nil, extractions); err == nil {
+		t.Error("getAllPages with the slice instead of the pointer: want error")
+	}
+}
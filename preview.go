@@ -0,0 +1,136 @@
+package rthrest
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+//ContentPreview : The sample returned by the request in PreviewModeContentEnum. Fields are the columns of the CSV header
+//and Rows are the sample rows in the same order
+type ContentPreview struct {
+	JobID  string
+	Fields []string
+	Rows   [][]string
+	Notes  *ExtractionNotes
+}
+
+//Maps : Return the rows as maps of the column names to the values
+func (p *ContentPreview) Maps() []map[string]string {
+	rows := make([]map[string]string, 0, len(p.Rows))
+	for _, row := range p.Rows {
+		values := make(map[string]string, len(p.Fields))
+		for i, field := range p.Fields {
+			if i < len(row) {
+				values[field] = row[i]
+			}
+		}
+		rows = append(rows, values)
+	}
+	return rows
+}
+
+//InstrumentPreview : The availability of the instruments returned by the request in PreviewModeInstrumentEnum.
+//Rejected contains the identifiers which are rejected by the identifier validation
+type InstrumentPreview struct {
+	JobID       string
+	Instruments []ManifestEntry
+	Rejected    []IdentifierValidationError
+	Notes       *ExtractionNotes
+}
+
+//Unavailable : Return the instruments whose Status is not Active, i.e. which have no data in the query range
+func (p *InstrumentPreview) Unavailable() []ManifestEntry {
+	var entries []ManifestEntry
+	for _, entry := range p.Instruments {
+		if !strings.EqualFold(entry.Status, "Active") {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+//previewRequest : Return a copy of the request in the preview mode. The original request is not changed
+func previewRequest(request *TickHistoryMarketDepthExtractionRequest, mode PreviewMode) *TickHistoryMarketDepthExtractionRequest {
+	preview := *request
+	preview.Condition.Preview = mode
+	return &preview
+}
+
+//readPreviewCSV : Read the header and at most maxRows rows of the decompressed result of the job. maxRows 0 reads all rows.
+//The '#' before the first column name is removed
+func (c *Client) readPreviewCSV(ctx context.Context, jobID string, maxRows int) ([]string, [][]string, error) {
+	reader, err := c.Stream(ctx, jobID, StreamOptions{Decompress: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer reader.Close()
+
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "#")
+	}
+
+	var rows [][]string
+	for maxRows <= 0 || len(rows) < maxRows {
+		row, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return header, rows, err
+		}
+		rows = append(rows, row)
+	}
+	return header, rows, nil
+}
+
+//PreviewContent : Run the request in PreviewModeContentEnum and return at most maxRows sample rows. maxRows 0 returns all sample rows.
+//It is used to check the field list before the full extraction
+func (c *Client) PreviewContent(ctx context.Context, request *TickHistoryMarketDepthExtractionRequest, maxRows int) (*ContentPreview, error) {
+	result, err := c.ExtractRaw(ctx, previewRequest(request, PreviewModeContentEnum), 0)
+	if err != nil {
+		return nil, err
+	}
+	preview := &ContentPreview{JobID: result.JobID, Notes: result.ParseNotes()}
+	preview.Fields, preview.Rows, err = c.readPreviewCSV(ctx, result.JobID, maxRows)
+	if err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+//PreviewInstruments : Run the request in PreviewModeInstrumentEnum and return the availability of each instrument.
+//It is used to check the coverage of the instruments before the full extraction.
+//If the result has no instrument, the manifest of the notes is used
+func (c *Client) PreviewInstruments(ctx context.Context, request *TickHistoryMarketDepthExtractionRequest) (*InstrumentPreview, error) {
+	result, err := c.ExtractRaw(ctx, previewRequest(request, PreviewModeInstrumentEnum), 0)
+	if err != nil {
+		return nil, err
+	}
+	preview := &InstrumentPreview{JobID: result.JobID, Rejected: result.IdentifierValidationErrors, Notes: result.ParseNotes()}
+
+	header, rows, err := c.readPreviewCSV(ctx, result.JobID, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if entry := manifestEntryFromRecord(header, row); entry != nil {
+			preview.Instruments = append(preview.Instruments, *entry)
+		}
+	}
+	if len(preview.Instruments) == 0 {
+		preview.Instruments = preview.Notes.Manifest
+	}
+	return preview, nil
+}
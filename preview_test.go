@@ -0,0 +1,53 @@
+package rthrest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPreviewInstruments(t *testing.T) {
+	csv := "#RIC,Domain,Start,End,Status,Count\n" +
+		"IBM.N,Market Price,2022-05-01T23:00:03.094264664Z,2022-05-01T23:57:10.474385281Z,Active,8\n" +
+		"\"BAD,X\",Market Price,,,\"Inactive, no data\",0\n"
+	var content bytes.Buffer
+	writer := gzip.NewWriter(&content)
+	writer.Write([]byte(csv))
+	writer.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			w.Write([]byte(`{"JobId":"0x1","Notes":["Extraction ID: 2000000431584047"]}`))
+			return
+		}
+		if !strings.Contains(r.URL.Path, "RawExtractionResults('0x1')") {
+			w.WriteHeader(404)
+			return
+		}
+		w.Write(content.Bytes())
+	}))
+	defer server.Close()
+	client := NewClient(server.Client(), server.URL+"/", false)
+
+	preview, err := client.PreviewInstruments(context.Background(), &TickHistoryMarketDepthExtractionRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(preview.Instruments) != 2 {
+		t.Fatalf("Instruments = %+v", preview.Instruments)
+	}
+	if entry := preview.Instruments[0]; entry.RIC != "IBM.N" || entry.Domain != "Market Price" || entry.Status != "Active" || entry.Count != 8 || entry.Start == nil {
+		t.Errorf("Instruments[0] = %+v", entry)
+	}
+	//The quoted fields with a comma stay in their columns
+	if entry := preview.Instruments[1]; entry.RIC != "BAD,X" || entry.Status != "Inactive, no data" || entry.Count != 0 {
+		t.Errorf("Instruments[1] = %+v", entry)
+	}
+	if unavailable := preview.Unavailable(); len(unavailable) != 1 || unavailable[0].RIC != "BAD,X" {
+		t.Errorf("Unavailable = %+v", unavailable)
+	}
+}
@@ -0,0 +1,122 @@
+package rthrest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
+	"time"
+)
+
+//ProbeResult : The information of the download URL discovered by Downloader.Probe.
+//Rate is the throughput of one connection measured while reading the probe range. It is 0 if it can't be measured
+type ProbeResult struct {
+	Size          int64
+	AcceptsRanges bool
+	ETag          string
+	Rate          float64
+}
+
+//Probe : Discover the size and range support of the download URL, e.g. RawExtractionResults('jobId')/$value or the AWS URL.
+//It sends GET request with 'Range: bytes=0-N' where N is ProbeSize - 1. The file size is taken from Content-Range (206) or Content-Length (200).
+//The probe range is read to measure the throughput of one connection.
+//AcceptsRanges is false for 200 even if Accept-Ranges header is returned, because the server ignored the Range header of the probe
+func (d *Downloader) Probe(url string) (*ProbeResult, error) {
+	return d.probe(d.newDownloadSource(url))
+}
+
+//probe : Probe the URL of the source. See Probe
+func (d *Downloader) probe(src *downloadSource) (*ProbeResult, error) {
+	probeSize := d.ProbeSize
+	if probeSize <= 0 {
+		probeSize = 1
+	}
+
+	start := time.Now()
+	resp, err := src.get(context.Background(), fmt.Sprintf("bytes=0-%d", probeSize-1))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &ProbeResult{ETag: resp.Header.Get("ETag")}
+	switch resp.StatusCode {
+	case 206:
+		_, _, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return nil, err
+		}
+		result.Size = size
+		result.AcceptsRanges = size > 0
+		n, err := io.Copy(ioutil.Discard, resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		if elapsed := time.Since(start); n > 0 && elapsed > 0 {
+			result.Rate = float64(n) / elapsed.Seconds()
+		}
+	case 200:
+		//The server ignores the Range header so the parts of ConcurrentDownload would fail.
+		//The body is not read because it is the whole file
+		result.Size = resp.ContentLength
+		result.AcceptsRanges = false
+		if result.Size < 0 {
+			result.Size = 0
+		}
+	default:
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
+	}
+	return result, nil
+}
+
+//PartCount : Choose the number of connections from the file size and the measured throughput.
+//Every part is at least MinPartSize bytes, and there are no more parts than the parts needed to download the file
+//in TargetPartDuration at the measured rate of one connection. The result is limited by MaxConnections
+func (d *Downloader) PartCount(probe *ProbeResult) int {
+	if probe == nil || !probe.AcceptsRanges || probe.Size <= 0 {
+		return 1
+	}
+
+	parts := int64(d.MaxConnections)
+	if parts < 1 {
+		parts = 1
+	}
+	if d.MinPartSize > 0 {
+		if bySize := probe.Size / d.MinPartSize; bySize < parts {
+			parts = bySize
+		}
+	}
+	if probe.Rate > 0 && d.TargetPartDuration > 0 {
+		byRate := int64(math.Ceil(float64(probe.Size) / (probe.Rate * d.TargetPartDuration.Seconds())))
+		if byRate < parts {
+			parts = byRate
+		}
+	}
+	if parts < 1 {
+		parts = 1
+	}
+	return int(parts)
+}
+
+//AutoDownload : Download the file without knowing its size in advance.
+//The URL is probed by Probe and the file is downloaded with ConcurrentDownload when the server supports range requests,
+//otherwise it is downloaded with one connection by Download
+func (d *Downloader) AutoDownload(url string, outFileName string) error {
+	//The same source is used by the probe and the download so the AWS URL is resolved only once
+	src := d.newDownloadSource(url)
+	probe, err := d.probe(src)
+	if err != nil {
+		log.Printf("AutoDownload: probe failed, download with one connection: %s\n", err.Error())
+		return d.download(src, outFileName, 0)
+	}
+
+	numOfConn := d.PartCount(probe)
+	log.Printf("AutoDownload: %s, Size: %d, Accept-Ranges: %t, Rate: %s/s, Connection: %d\n", outFileName, probe.Size, probe.AcceptsRanges, formatBytes(int64(probe.Rate)), numOfConn)
+	if numOfConn <= 1 {
+		return d.download(src, outFileName, probe.Size)
+	}
+	return d.concurrentDownload(src, outFileName, numOfConn, probe.Size)
+}
@@ -0,0 +1,147 @@
+package rthrest
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProbe(t *testing.T) {
+	content := testGzipContent(t, 64*1024)
+	size := int64(len(content))
+
+	tests := []struct {
+		name          string
+		noRange       bool
+		acceptsRanges bool
+	}{
+		//206: the size is taken from Content-Range
+		{name: "206", acceptsRanges: true},
+		//200: the server sends Accept-Ranges but ignores the Range header
+		{name: "200", noRange: true},
+	}
+	for _, test := range tests {
+		server := newTestFileServer(t, content)
+		server.noRange = test.noRange
+		downloader := newTestDownloader(server)
+		downloader.ProbeSize = 1024
+
+		probe, err := downloader.Probe(server.URL)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err.Error())
+		}
+		if probe.Size != size || probe.AcceptsRanges != test.acceptsRanges || probe.ETag != server.etag {
+			t.Errorf("%s: Probe = %+v, want Size %d, AcceptsRanges %v", test.name, probe, size, test.acceptsRanges)
+		}
+		if test.acceptsRanges && probe.Rate <= 0 {
+			t.Errorf("%s: Rate = %f", test.name, probe.Rate)
+		}
+		if ranges := server.requests(); len(ranges) != 1 || ranges[0] != "bytes=0-1023" {
+			t.Errorf("%s: Range headers = %q", test.name, ranges)
+		}
+	}
+
+	server := newTestFileServer(t, content)
+	server.Config.Handler = http.NotFoundHandler()
+	if _, err := newTestDownloader(server).Probe(server.URL); err == nil {
+		t.Error("Probe of 404: want error")
+	} else if statusErr, ok := err.(*HTTPStatusError); !ok || statusErr.StatusCode != 404 {
+		t.Errorf("Probe of 404 = %v, want HTTPStatusError", err)
+	}
+}
+
+func TestPartCount(t *testing.T) {
+	const mb = 1024 * 1024
+	downloader := &Downloader{MaxConnections: 8, MinPartSize: 16 * mb, TargetPartDuration: 10 * time.Second}
+	tests := []struct {
+		name  string
+		probe *ProbeResult
+		want  int
+	}{
+		{name: "no probe", want: 1},
+		{name: "no range", probe: &ProbeResult{Size: 1024 * mb}, want: 1},
+		{name: "unknown size", probe: &ProbeResult{AcceptsRanges: true}, want: 1},
+		//MinPartSize: 40MB has 2 parts of at least 16MB
+		{name: "MinPartSize", probe: &ProbeResult{Size: 40 * mb, AcceptsRanges: true}, want: 2},
+		{name: "smaller than MinPartSize", probe: &ProbeResult{Size: 10 * mb, AcceptsRanges: true}, want: 1},
+		//MaxConnections: 1GB has 64 parts of 16MB
+		{name: "MaxConnections", probe: &ProbeResult{Size: 1024 * mb, AcceptsRanges: true}, want: 8},
+		//Rate: 1GB at 20MB/s is downloaded in 10 seconds with 6 connections
+		{name: "rate", probe: &ProbeResult{Size: 1024 * mb, AcceptsRanges: true, Rate: 20 * mb}, want: 6},
+		{name: "fast connection", probe: &ProbeResult{Size: 1024 * mb, AcceptsRanges: true, Rate: 200 * mb}, want: 1},
+	}
+	for _, test := range tests {
+		if got := downloader.PartCount(test.probe); got != test.want {
+			t.Errorf("%s: PartCount = %d, want %d", test.name, got, test.want)
+		}
+	}
+
+	noLimit := &Downloader{}
+	if got := noLimit.PartCount(&ProbeResult{Size: 1024 * mb, AcceptsRanges: true}); got != 1 {
+		t.Errorf("PartCount without MaxConnections = %d, want 1", got)
+	}
+}
+
+func TestAutoDownload(t *testing.T) {
+	content := testGzipContent(t, 256*1024)
+	size := int64(len(content))
+
+	tests := []struct {
+		name       string
+		noRange    bool
+		probeFails bool
+		wantRanges []string
+	}{
+		{name: "concurrent", wantRanges: []string{"bytes=0-1023", fmt.Sprintf("bytes=0-%d", size/2-1), fmt.Sprintf("bytes=%d-%d", size/2, size-1)}},
+		//200 for the probe: the file is downloaded with one connection instead of failing every part
+		{name: "range not supported", noRange: true, wantRanges: []string{"bytes=0-1023", ""}},
+		{name: "probe fails", probeFails: true, wantRanges: []string{"bytes=0-1023", ""}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestFileServer(t, content)
+			server.noRange = test.noRange
+			if test.probeFails {
+				var requests int32
+				server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if atomic.AddInt32(&requests, 1) == 1 {
+						server.mutex.Lock()
+						server.ranges = append(server.ranges, r.Header.Get("Range"))
+						server.mutex.Unlock()
+						http.Error(w, "Service Unavailable", 503)
+						return
+					}
+					server.serve(w, r)
+				})
+			}
+			downloader := newTestDownloader(server)
+			downloader.ProbeSize = 1024
+			downloader.MinPartSize = size / 2
+			//The rate of the local server would choose one connection
+			downloader.TargetPartDuration = 0
+			outFileName := filepath.Join(t.TempDir(), "output.csv.gz")
+
+			if err := downloader.AutoDownload(server.URL, outFileName); err != nil {
+				t.Fatal(err)
+			}
+			checkDownloadedFile(t, outFileName, content)
+			ranges := server.requests()
+			if len(ranges) != len(test.wantRanges) {
+				t.Fatalf("Range headers = %q, want %q", ranges, test.wantRanges)
+			}
+			//The parts are requested concurrently so the order is not checked
+			for _, want := range test.wantRanges {
+				found := false
+				for _, r := range ranges {
+					found = found || r == want
+				}
+				if !found {
+					t.Errorf("Range headers = %q, want %q", ranges, test.wantRanges)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package rthrest
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//DownloadProgress : The progress of a download aggregated across all parts.
+//Bytes includes the bytes downloaded by the previous run when the download is resumed.
+//Rate is the average bytes per second of this run and ETA is estimated from Rate. Total is 0 if the size is unknown.
+//Err is the error which stopped the download. It is set only when Done is true and the download fails
+type DownloadProgress struct {
+	FileName string
+	Bytes    int64
+	Total    int64
+	Rate     float64
+	ETA      time.Duration
+	Elapsed  time.Duration
+	Done     bool
+	Err      error
+}
+
+//Percent : Return the percentage of downloaded bytes. It returns 0 if the total is unknown
+func (p DownloadProgress) Percent() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return float64(p.Bytes) / float64(p.Total) * 100
+}
+
+//ProgressReporter : The interface used by Downloader to report the download progress.
+//Report is called periodically while the file is downloaded and once with Done set to true when the download stops
+type ProgressReporter interface {
+	Report(progress DownloadProgress)
+}
+
+//NoProgressReporter : ProgressReporter which discards the progress. It is used by services which don't need progress output
+type NoProgressReporter struct{}
+
+//Report : Discard the progress
+func (NoProgressReporter) Report(progress DownloadProgress) {}
+
+//LogProgressReporter : ProgressReporter which writes a log line every Interval and when the download stops
+type LogProgressReporter struct {
+	Interval time.Duration
+	mutex    sync.Mutex
+	last     map[string]time.Time
+}
+
+//NewLogProgressReporter : Create LogProgressReporter which writes a log line every interval
+func NewLogProgressReporter(interval time.Duration) *LogProgressReporter {
+	return &LogProgressReporter{Interval: interval, last: make(map[string]time.Time)}
+}
+
+//Report : Write the progress to the log if the interval is passed
+func (r *LogProgressReporter) Report(progress DownloadProgress) {
+	if progress.Done && progress.Err != nil {
+		log.Printf("%s: Download Failed, %s in %s: %s", progress.FileName, formatBytes(progress.Bytes), progress.Elapsed.Round(time.Millisecond), progress.Err.Error())
+		return
+	}
+	if progress.Done {
+		log.Printf("%s: Download Completed, %s in %s, Speed: Avg %s/s", progress.FileName, formatBytes(progress.Bytes), progress.Elapsed.Round(time.Millisecond), formatBytes(int64(progress.Rate)))
+		return
+	}
+
+	r.mutex.Lock()
+	if r.last == nil {
+		r.last = make(map[string]time.Time)
+	}
+	if time.Since(r.last[progress.FileName]) < r.Interval {
+		r.mutex.Unlock()
+		return
+	}
+	r.last[progress.FileName] = time.Now()
+	r.mutex.Unlock()
+
+	if progress.Total > 0 {
+		log.Printf("%s, Bytes: %d/Total: %d (%.0f%%), %s/s, ETA %s", progress.FileName, progress.Bytes, progress.Total, progress.Percent(), formatBytes(int64(progress.Rate)), progress.ETA.Round(time.Second))
+	} else {
+		log.Printf("%s, Bytes: %d, %s/s", progress.FileName, progress.Bytes, formatBytes(int64(progress.Rate)))
+	}
+}
+
+//TerminalProgressReporter : ProgressReporter which draws a progress bar on a terminal. Writer is os.Stderr if it is nil
+type TerminalProgressReporter struct {
+	Writer io.Writer
+	Width  int
+}
+
+//Report : Redraw the progress bar on the same line
+func (r *TerminalProgressReporter) Report(progress DownloadProgress) {
+	writer := r.Writer
+	if writer == nil {
+		writer = os.Stderr
+	}
+	width := r.Width
+	if width <= 0 {
+		width = 40
+	}
+
+	filled := 0
+	if progress.Total > 0 {
+		filled = int(float64(width) * float64(progress.Bytes) / float64(progress.Total))
+		if filled > width {
+			filled = width
+		}
+	}
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", width-filled)
+	fmt.Fprintf(writer, "\r%s [%s] %3.0f%% %s %s/s ETA %s   ", progress.FileName, bar, progress.Percent(), formatBytes(progress.Bytes), formatBytes(int64(progress.Rate)), progress.ETA.Round(time.Second))
+	if progress.Done {
+		if progress.Err != nil {
+			fmt.Fprintf(writer, "Failed: %s", progress.Err.Error())
+		}
+		fmt.Fprintln(writer)
+	}
+}
+
+//formatBytes : Convert the number of bytes to a human readable string, e.g. 1.5 MB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+//progressTracker : io.Writer which counts the written bytes and reports the progress periodically.
+//It is shared by all parts of the concurrent download
+type progressTracker struct {
+	bytes    int64
+	initial  int64
+	total    int64
+	fileName string
+	reporter ProgressReporter
+	start    time.Time
+	stopped  chan struct{}
+	wg       sync.WaitGroup
+}
+
+//startProgress : Start reporting the progress of the file. initial is the number of bytes downloaded by the previous run
+func (d *Downloader) startProgress(fileName string, total int64, initial int64) *progressTracker {
+	reporter := d.Progress
+	if reporter == nil {
+		reporter = NoProgressReporter{}
+	}
+	interval := d.ProgressInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	t := &progressTracker{
+		bytes:    initial,
+		initial:  initial,
+		total:    total,
+		fileName: fileName,
+		reporter: reporter,
+		start:    time.Now(),
+		stopped:  make(chan struct{}),
+	}
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-t.stopped:
+				return
+			case <-ticker.C:
+				t.reporter.Report(t.snapshot(false, nil))
+			}
+		}
+	}()
+	return t
+}
+
+//Write : Count the bytes written to the output file
+func (t *progressTracker) Write(p []byte) (int, error) {
+	atomic.AddInt64(&t.bytes, int64(len(p)))
+	return len(p), nil
+}
+
+//snapshot : Calculate the rate and ETA from the counted bytes
+func (t *progressTracker) snapshot(done bool, err error) DownloadProgress {
+	bytes := atomic.LoadInt64(&t.bytes)
+	elapsed := time.Since(t.start)
+	progress := DownloadProgress{
+		FileName: t.fileName,
+		Bytes:    bytes,
+		Total:    t.total,
+		Elapsed:  elapsed,
+		Done:     done,
+		Err:      err,
+	}
+	if elapsed > 0 {
+		progress.Rate = float64(bytes-t.initial) / elapsed.Seconds()
+	}
+	if progress.Rate > 0 && t.total > bytes {
+		progress.ETA = time.Duration(float64(t.total-bytes) / progress.Rate * float64(time.Second))
+	}
+	return progress
+}
+
+//stop : Stop the periodic report and report the final progress. err is the error which stopped the download
+func (t *progressTracker) stop(err error) {
+	close(t.stopped)
+	t.wg.Wait()
+	t.reporter.Report(t.snapshot(true, err))
+}
@@ -0,0 +1,199 @@
+package rthrest
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+//testProgressReporter : ProgressReporter which records the reported progress
+type testProgressReporter struct {
+	mutex    sync.Mutex
+	progress []DownloadProgress
+}
+
+func (r *testProgressReporter) Report(progress DownloadProgress) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.progress = append(r.progress, progress)
+}
+
+//done : Return the progress reported with Done. It fails the test unless there is exactly one
+func (r *testProgressReporter) done(t *testing.T) DownloadProgress {
+	t.Helper()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var done []DownloadProgress
+	for _, progress := range r.progress {
+		if progress.Done {
+			done = append(done, progress)
+		}
+	}
+	if len(done) != 1 {
+		t.Fatalf("%d progress with Done, want 1", len(done))
+	}
+	return done[0]
+}
+
+//captureLog : Redirect the log output to the buffer until the test ends
+func captureLog(t *testing.T) *bytes.Buffer {
+	var buffer bytes.Buffer
+	log.SetOutput(&buffer)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buffer
+}
+
+func TestProgressTrackerSumsParts(t *testing.T) {
+	reporter := &testProgressReporter{}
+	d := &Downloader{Progress: reporter, ProgressInterval: time.Millisecond}
+	tracker := d.startProgress("output.csv.gz", 8*1000+500, 500)
+
+	var wg sync.WaitGroup
+	for part := 0; part < 8; part++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				tracker.Write(make([]byte, 10))
+			}
+		}()
+	}
+	wg.Wait()
+	tracker.stop(nil)
+
+	done := reporter.done(t)
+	if done.Bytes != 8*1000+500 || done.Total != 8*1000+500 || done.Err != nil || done.ETA != 0 || done.Percent() != 100 {
+		t.Errorf("Done = %+v", done)
+	}
+	//The periodic reports never go back
+	var last int64
+	for _, progress := range reporter.progress {
+		if progress.Bytes < last || progress.Bytes < 500 {
+			t.Errorf("Bytes = %d after %d", progress.Bytes, last)
+		}
+		last = progress.Bytes
+	}
+}
+
+func TestConcurrentDownloadProgress(t *testing.T) {
+	content := testGzipContent(t, 256*1024)
+	size := int64(len(content))
+	server := newTestFileServer(t, content)
+	reporter := &testProgressReporter{}
+	downloader := newTestDownloader(server)
+	downloader.Progress = reporter
+
+	if err := downloader.ConcurrentDownload(server.URL, filepath.Join(t.TempDir(), "output.csv.gz"), 4, size); err != nil {
+		t.Fatal(err)
+	}
+	//The parts are reported as one file
+	if done := reporter.done(t); done.Bytes != size || done.Total != size || done.Err != nil {
+		t.Errorf("Done = %+v, want %d bytes", done, size)
+	}
+}
+
+func TestDownloadFailureProgress(t *testing.T) {
+	content := testGzipContent(t, 256*1024)
+	size := int64(len(content))
+	tests := []struct {
+		name     string
+		download func(d *Downloader, url string, outFileName string) error
+	}{
+		{name: "Download", download: func(d *Downloader, url string, outFileName string) error {
+			return d.Download(url, outFileName, size)
+		}},
+		{name: "ConcurrentDownload", download: func(d *Downloader, url string, outFileName string) error {
+			return d.ConcurrentDownload(url, outFileName, 2, size)
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestFileServer(t, content)
+			server.setCutAt(size/2 + 1024)
+			logOutput := captureLog(t)
+			reporter := &testProgressReporter{}
+			downloader := newTestDownloader(server)
+			downloader.Progress = reporter
+
+			err := test.download(downloader, server.URL, filepath.Join(t.TempDir(), "output.csv.gz"))
+			if err == nil {
+				t.Fatal("the interrupted download returns no error")
+			}
+			done := reporter.done(t)
+			if done.Err == nil || done.Bytes >= size {
+				t.Errorf("Done = %+v, want the error", done)
+			}
+
+			NewLogProgressReporter(time.Second).Report(done)
+			if strings.Contains(logOutput.String(), "output.csv.gz: Download Completed") || !strings.Contains(logOutput.String(), "output.csv.gz: Download Failed") {
+				t.Errorf("log =\n%s", logOutput.String())
+			}
+		})
+	}
+}
+
+func TestLogProgressReporter(t *testing.T) {
+	logOutput := captureLog(t)
+	reporter := NewLogProgressReporter(time.Hour)
+
+	reporter.Report(DownloadProgress{FileName: "a.csv.gz", Bytes: 512, Total: 1024})
+	//The second report of the same file within the interval is not written
+	reporter.Report(DownloadProgress{FileName: "a.csv.gz", Bytes: 768, Total: 1024})
+	reporter.Report(DownloadProgress{FileName: "b.csv.gz", Bytes: 100})
+	reporter.Report(DownloadProgress{FileName: "a.csv.gz", Bytes: 1024, Total: 1024, Rate: 2048, Elapsed: 500 * time.Millisecond, Done: true})
+
+	lines := strings.Split(strings.TrimSpace(logOutput.String()), "\n")
+	want := []string{
+		"a.csv.gz, Bytes: 512/Total: 1024 (50%)",
+		"b.csv.gz, Bytes: 100,",
+		"a.csv.gz: Download Completed, 1.0 KB in 500ms, Speed: Avg 2.0 KB/s",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("log =\n%s", logOutput.String())
+	}
+	for i := range want {
+		if !strings.Contains(lines[i], want[i]) {
+			t.Errorf("line %d = %q, want %q", i+1, lines[i], want[i])
+		}
+	}
+}
+
+func TestTerminalProgressReporter(t *testing.T) {
+	var output bytes.Buffer
+	reporter := &TerminalProgressReporter{Writer: &output, Width: 10}
+
+	reporter.Report(DownloadProgress{FileName: "a.csv.gz", Bytes: 512, Total: 1024})
+	if !strings.HasPrefix(output.String(), "\ra.csv.gz [=====     ]  50%") || strings.HasSuffix(output.String(), "\n") {
+		t.Errorf("output = %q", output.String())
+	}
+	output.Reset()
+	reporter.Report(DownloadProgress{FileName: "a.csv.gz", Bytes: 600, Total: 1024, Done: true, Err: errors.New("connection reset")})
+	if !strings.HasSuffix(output.String(), "Failed: connection reset\n") {
+		t.Errorf("output = %q", output.String())
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{5 * 1024 * 1024, "5.0 MB"},
+		{3 * 1024 * 1024 * 1024, "3.0 GB"},
+	}
+	for _, test := range tests {
+		if got := formatBytes(test.n); got != test.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", test.n, got, test.want)
+		}
+	}
+}
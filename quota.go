@@ -0,0 +1,188 @@
+package rthrest
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+//QuotaPolicy : This is an enumeration for the way CheckQuota handles a request which exceeds the allowed share of the remaining quota
+type QuotaPolicy int
+
+//Available Enumerations for QuotaPolicy
+const (
+	QuotaPolicyFailEnum QuotaPolicy = iota
+	QuotaPolicyWarnEnum
+	QuotaPolicyIgnoreEnum
+)
+
+//Enumeration String of quotaPolicy enumeration used by String and ParseQuotaPolicy
+var quotaPolicy = [...]string{
+	"fail",
+	"warn",
+	"ignore",
+}
+
+//String : It uses quotaPolicy string array variable to convert int (enum) to string
+func (p QuotaPolicy) String() string {
+	return quotaPolicy[p]
+}
+
+//ParseQuotaPolicy : Convert the policy name (fail, warn, ignore) to QuotaPolicy
+func ParseQuotaPolicy(name string) (QuotaPolicy, error) {
+	for i, v := range quotaPolicy {
+		if strings.EqualFold(v, name) {
+			return QuotaPolicy(i), nil
+		}
+	}
+	return QuotaPolicyFailEnum, fmt.Errorf("unknown quota policy: %q", name)
+}
+
+//QuotaInformation : defined type for the quota of the user returned by Quota/GetQuotaInformation.
+//MaxCount is 0 if the category is not limited
+type QuotaInformation struct {
+	QuotaCategory string
+	QuotaType     string
+	MaxCount      int64
+	CurrentCount  int64
+	Message       string
+}
+
+//Remaining : Return the remaining count of the quota. It returns -1 if the quota is not limited
+func (q *QuotaInformation) Remaining() int64 {
+	if q.MaxCount <= 0 {
+		return -1
+	}
+	if q.CurrentCount >= q.MaxCount {
+		return 0
+	}
+	return q.MaxCount - q.CurrentCount
+}
+
+//QuotaCheckOptions : defined type for the policy applied by CheckQuota
+type QuotaCheckOptions struct {
+	Policy QuotaPolicy
+	//MaxShare is the highest accepted ratio (0.0 - 1.0) of the remaining quota used by one request. The zero value means 1.0
+	MaxShare float64
+	//Category is the QuotaCategory used by the check. If it is empty, the first category which contains "TickHistory" is used
+	Category string
+}
+
+//QuotaExceededError : The error returned by CheckQuota when the estimated cost of the request exceeds the allowed share of the remaining quota
+type QuotaExceededError struct {
+	Category  string
+	Estimate  int64
+	Remaining int64
+	MaxShare  float64
+}
+
+//Error : Return the estimated cost and the remaining quota
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("quota %s: the request may use %d instrument-days but only %.0f%% of the remaining %d is allowed", e.Category, e.Estimate, e.MaxShare*100, e.Remaining)
+}
+
+//EstimateInstrumentDays : Estimate the cost of the request as the number of instruments multiplied by the number of days in the query range.
+//It is an upper bound because the days without data, e.g. weekends, are also counted.
+//Relative ranges use DaysAgo, RelativeStartDaysAgo and RelativeEndDaysAgo. Other ranges are counted as one day
+func EstimateInstrumentDays(request *TickHistoryMarketDepthExtractionRequest) int64 {
+	instruments := int64(len(request.IdentifierList.InstrumentIdentifiers))
+	return instruments * estimateDays(&request.Condition)
+}
+
+//estimateDays : Return the number of calendar days in the query range of the condition.
+//The day which starts at QueryEndDate is not counted when QueryEndDate is midnight, e.g. 2017-08-21T00:00:00Z - 2017-08-22T00:00:00Z is one day
+func estimateDays(condition *TickHistoryMarketDepthCondition) int64 {
+	switch {
+	case condition.ReportDateRangeType == ReportDateRangeTypeRangeEnum && condition.QueryStartDate != nil && condition.QueryEndDate != nil:
+		queryStart, queryEnd := condition.QueryStartDate.UTC(), condition.QueryEndDate.UTC()
+		if queryEnd.Before(queryStart) {
+			return 0
+		}
+		if queryEnd.After(queryStart) && queryEnd.Equal(queryEnd.Truncate(24*time.Hour)) {
+			queryEnd = queryEnd.Add(-time.Nanosecond)
+		}
+		start := queryStart.Truncate(24 * time.Hour)
+		end := queryEnd.Truncate(24 * time.Hour)
+		return int64(end.Sub(start)/(24*time.Hour)) + 1
+	case condition.RelativeStartDaysAgo > 0:
+		if condition.RelativeEndDaysAgo > condition.RelativeStartDaysAgo {
+			return 0
+		}
+		return int64(condition.RelativeStartDaysAgo-condition.RelativeEndDaysAgo) + 1
+	case condition.DaysAgo > 0:
+		return int64(condition.DaysAgo)
+	default:
+		return 1
+	}
+}
+
+//GetQuotaInformation : Get the quota and the current usage of the user from Quota/GetQuotaInformation
+func (c *Client) GetQuotaInformation() ([]QuotaInformation, error) {
+	var quotas struct {
+		Value []QuotaInformation `json:"value"`
+	}
+	if err := c.getJSON(GetQuotaInformationURL(c.URL), &quotas); err != nil {
+		return nil, err
+	}
+	return quotas.Value, nil
+}
+
+//FindQuota : Return the quota of the category. If category is empty, the first category which contains "TickHistory" is returned.
+//It returns nil if there is no such category
+func FindQuota(quotas []QuotaInformation, category string) *QuotaInformation {
+	for i := range quotas {
+		if category == "" && strings.Contains(strings.ToLower(quotas[i].QuotaCategory), "tickhistory") {
+			return &quotas[i]
+		}
+		if category != "" && strings.EqualFold(quotas[i].QuotaCategory, category) {
+			return &quotas[i]
+		}
+	}
+	return nil
+}
+
+//CheckQuotaEstimate : Apply the policy to the estimated cost of the request and the quota.
+//It returns QuotaExceededError if the policy is QuotaPolicyFailEnum and the estimate is greater than MaxShare of the remaining quota
+func CheckQuotaEstimate(estimate int64, quota *QuotaInformation, options QuotaCheckOptions) error {
+	if options.Policy == QuotaPolicyIgnoreEnum || quota == nil {
+		return nil
+	}
+	remaining := quota.Remaining()
+	if remaining < 0 {
+		return nil
+	}
+	maxShare := options.MaxShare
+	if maxShare <= 0 || maxShare > 1 {
+		maxShare = 1
+	}
+	log.Printf("Quota %s: Used %d of %d, Remaining: %d, Estimate: %d instrument-days\n", quota.QuotaCategory, quota.CurrentCount, quota.MaxCount, remaining, estimate)
+	if float64(estimate) <= float64(remaining)*maxShare {
+		return nil
+	}
+
+	err := &QuotaExceededError{Category: quota.QuotaCategory, Estimate: estimate, Remaining: remaining, MaxShare: maxShare}
+	if options.Policy == QuotaPolicyWarnEnum {
+		log.Printf("Warning: %s\n", err.Error())
+		return nil
+	}
+	return err
+}
+
+//CheckQuota : Estimate the cost of the request by EstimateInstrumentDays and compare it with the remaining quota of the user before ExtractRaw.
+//See CheckQuotaEstimate. If the quota category is not found, the check is skipped
+func (c *Client) CheckQuota(request *TickHistoryMarketDepthExtractionRequest, options QuotaCheckOptions) error {
+	if options.Policy == QuotaPolicyIgnoreEnum {
+		return nil
+	}
+	quotas, err := c.GetQuotaInformation()
+	if err != nil {
+		return err
+	}
+	quota := FindQuota(quotas, options.Category)
+	if quota == nil {
+		log.Printf("Quota %q is not found, skip the quota check\n", options.Category)
+		return nil
+	}
+	return CheckQuotaEstimate(EstimateInstrumentDays(request), quota, options)
+}
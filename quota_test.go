@@ -0,0 +1,124 @@
+package rthrest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEstimateInstrumentDays(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     string
+		end       string
+		rangeType ReportDateRangeType
+		condition TickHistoryMarketDepthCondition
+		want      int64
+	}{
+		{name: "same day", start: "2017-08-21T09:00:00Z", end: "2017-08-21T17:00:00Z", want: 1},
+		{name: "same instant", start: "2017-08-21T00:00:00Z", end: "2017-08-21T00:00:00Z", want: 1},
+		//The range ending at midnight doesn't include the next day
+		{name: "midnight end", start: "2017-08-21T00:00:00Z", end: "2017-08-22T00:00:00Z", want: 1},
+		{name: "after midnight", start: "2017-08-21T00:00:00Z", end: "2017-08-22T00:00:00.000000001Z", want: 2},
+		{name: "multi-day", start: "2017-08-21T09:00:00Z", end: "2017-08-23T17:00:00Z", want: 3},
+		{name: "multi-day midnight end", start: "2017-08-21T09:00:00Z", end: "2017-08-24T00:00:00Z", want: 3},
+		//The dates are counted in UTC
+		{name: "time zone", start: "2017-08-21T23:00:00-02:00", end: "2017-08-22T01:00:00-02:00", want: 1},
+		{name: "end before start", start: "2017-08-22T00:00:00Z", end: "2017-08-21T00:00:00Z", want: 0},
+		{name: "nil dates", rangeType: ReportDateRangeTypeRangeEnum, want: 1},
+		{name: "nil end", start: "2017-08-21T00:00:00Z", want: 1},
+		{name: "relative", condition: TickHistoryMarketDepthCondition{RelativeStartDaysAgo: 7, RelativeEndDaysAgo: 1}, want: 7},
+		{name: "relative end before start", condition: TickHistoryMarketDepthCondition{RelativeStartDaysAgo: 1, RelativeEndDaysAgo: 7}, want: 0},
+		{name: "days ago", condition: TickHistoryMarketDepthCondition{DaysAgo: 5}, want: 5},
+	}
+	for _, test := range tests {
+		request := &TickHistoryMarketDepthExtractionRequest{Condition: test.condition}
+		if test.start != "" || test.rangeType == ReportDateRangeTypeRangeEnum {
+			request.Condition.ReportDateRangeType = ReportDateRangeTypeRangeEnum
+		}
+		if test.start != "" {
+			start := testTime(t, test.start)
+			request.Condition.QueryStartDate = &start
+		}
+		if test.end != "" {
+			end := testTime(t, test.end)
+			request.Condition.QueryEndDate = &end
+		}
+		request.IdentifierList.InstrumentIdentifiers = []InstrumentIdentifier{
+			{Identifier: "VOD.L", IdentifierType: "Ric"},
+			{Identifier: "IBM.N", IdentifierType: "Ric"},
+		}
+		if got := EstimateInstrumentDays(request); got != 2*test.want {
+			t.Errorf("%s: EstimateInstrumentDays = %d, want %d", test.name, got, 2*test.want)
+		}
+	}
+}
+
+func TestCheckQuotaEstimate(t *testing.T) {
+	quota := &QuotaInformation{QuotaCategory: "TickHistoryCash", MaxCount: 500, CurrentCount: 400}
+	tests := []struct {
+		name     string
+		estimate int64
+		quota    *QuotaInformation
+		options  QuotaCheckOptions
+		fail     bool
+	}{
+		{name: "fail within remaining", estimate: 100, quota: quota},
+		{name: "fail above remaining", estimate: 101, quota: quota, fail: true},
+		{name: "fail at max share", estimate: 50, quota: quota, options: QuotaCheckOptions{MaxShare: 0.5}},
+		{name: "fail above max share", estimate: 51, quota: quota, options: QuotaCheckOptions{MaxShare: 0.5}, fail: true},
+		{name: "max share above 1", estimate: 101, quota: quota, options: QuotaCheckOptions{MaxShare: 2}, fail: true},
+		{name: "warn above remaining", estimate: 101, quota: quota, options: QuotaCheckOptions{Policy: QuotaPolicyWarnEnum}},
+		{name: "ignore above remaining", estimate: 101, quota: quota, options: QuotaCheckOptions{Policy: QuotaPolicyIgnoreEnum}},
+		{name: "used up", estimate: 1, quota: &QuotaInformation{QuotaCategory: "TickHistoryCash", MaxCount: 500, CurrentCount: 620}, fail: true},
+		{name: "not limited", estimate: 1000000, quota: &QuotaInformation{QuotaCategory: "TickHistoryCash"}},
+		{name: "no quota", estimate: 1000000},
+	}
+	for _, test := range tests {
+		err := CheckQuotaEstimate(test.estimate, test.quota, test.options)
+		if !test.fail {
+			if err != nil {
+				t.Errorf("%s: %s", test.name, err.Error())
+			}
+			continue
+		}
+		exceeded, ok := err.(*QuotaExceededError)
+		if !ok {
+			t.Errorf("%s: error = %v, want QuotaExceededError", test.name, err)
+			continue
+		}
+		if exceeded.Estimate != test.estimate || exceeded.Remaining != test.quota.Remaining() {
+			t.Errorf("%s: error = %+v", test.name, exceeded)
+		}
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "GetQuotaInformation") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"value":[
+			{"QuotaCategory":"DataScopeSelect","QuotaType":"Instrument","MaxCount":0,"CurrentCount":10},
+			{"QuotaCategory":"TickHistoryCash","QuotaType":"Instrument","MaxCount":500,"CurrentCount":499}]}`))
+	}))
+	defer server.Close()
+	client := NewClient(server.Client(), server.URL+"/", false)
+
+	request := &TickHistoryMarketDepthExtractionRequest{}
+	request.Condition.DaysAgo = 2
+	request.IdentifierList.InstrumentIdentifiers = []InstrumentIdentifier{{Identifier: "VOD.L", IdentifierType: "Ric"}}
+
+	//The TickHistory category is used by default
+	if err := client.CheckQuota(request, QuotaCheckOptions{}); err == nil {
+		t.Error("CheckQuota of 2 instrument-days with 1 remaining: want error")
+	}
+	if err := client.CheckQuota(request, QuotaCheckOptions{Category: "DataScopeSelect"}); err != nil {
+		t.Errorf("CheckQuota of the unlimited category: %s", err.Error())
+	}
+	if err := client.CheckQuota(request, QuotaCheckOptions{Category: "Unknown"}); err != nil {
+		t.Errorf("CheckQuota of the unknown category: %s", err.Error())
+	}
+}
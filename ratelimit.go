@@ -0,0 +1,131 @@
+package rthrest
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+//minLimiterChunk : The smallest number of bytes read at once by the limited reader
+const minLimiterChunk = 4096
+
+//BandwidthLimiter : Token bucket which limits the total bytes per second read by all downloads and streams that share it.
+//The rate can be changed at runtime by SetRate. The rate 0 means unlimited
+type BandwidthLimiter struct {
+	mutex  sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+//NewBandwidthLimiter : Create BandwidthLimiter with the rate in bytes per second
+func NewBandwidthLimiter(bytesPerSecond int64) *BandwidthLimiter {
+	l := &BandwidthLimiter{}
+	l.SetRate(bytesPerSecond)
+	return l
+}
+
+//SetRate : Change the rate in bytes per second. The rate 0 disables the limit
+func (l *BandwidthLimiter) SetRate(bytesPerSecond int64) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if bytesPerSecond < 0 {
+		bytesPerSecond = 0
+	}
+	l.rate = float64(bytesPerSecond)
+	if l.tokens > l.rate {
+		l.tokens = l.rate
+	}
+	l.last = time.Now()
+}
+
+//Rate : Return the current rate in bytes per second
+func (l *BandwidthLimiter) Rate() int64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return int64(l.rate)
+}
+
+//chunkSize : The number of bytes read at once. It is a tenth of the rate so the bandwidth is shared fairly by the readers
+func (l *BandwidthLimiter) chunkSize() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.rate == 0 {
+		return 0
+	}
+	chunk := int(l.rate / 10)
+	if chunk < minLimiterChunk {
+		chunk = minLimiterChunk
+	}
+	return chunk
+}
+
+//WaitN : Take n tokens from the bucket and block until they are available or the context is done
+func (l *BandwidthLimiter) WaitN(ctx context.Context, n int) error {
+	l.mutex.Lock()
+	if l.rate == 0 {
+		l.mutex.Unlock()
+		return nil
+	}
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	//The bucket holds at most one second of tokens
+	if l.tokens > l.rate {
+		l.tokens = l.rate
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mutex.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+//Reader : Return io.Reader which is limited by the BandwidthLimiter. It returns the reader itself if the limiter is nil
+func (l *BandwidthLimiter) Reader(ctx context.Context, r io.Reader) io.Reader {
+	if l == nil {
+		return r
+	}
+	return &limitedReader{ctx: ctx, reader: r, limiter: l}
+}
+
+//limitedReader : io.Reader which waits for the tokens of the BandwidthLimiter after each read
+type limitedReader struct {
+	ctx     context.Context
+	reader  io.Reader
+	limiter *BandwidthLimiter
+}
+
+//Read : Read at most one chunk and wait until the bytes are allowed by the limiter
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if chunk := r.limiter.chunkSize(); chunk > 0 && len(p) > chunk {
+		p = p[:chunk]
+	}
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil && err == nil {
+			err = waitErr
+		}
+	}
+	return n, err
+}
+
+//limitedReadCloser : io.ReadCloser which reads from the limited reader and closes the original reader
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,121 @@
+package rthrest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBandwidthLimiterSharedByReaders(t *testing.T) {
+	//The bucket starts empty so 4 readers of 25KB take about 0.5 seconds at 200KB/s together.
+	//Each reader alone would take 0.125 seconds
+	limiter := NewBandwidthLimiter(200 * 1024)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := io.Copy(ioutil.Discard, limiter.Reader(context.Background(), bytes.NewReader(make([]byte, 25*1024))))
+			if err != nil || n != 25*1024 {
+				t.Errorf("Copy = %d, %v", n, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond || elapsed > 3*time.Second {
+		t.Errorf("100KB at 200KB/s in %s, want about 500ms", elapsed)
+	}
+}
+
+func TestBandwidthLimiterSetRate(t *testing.T) {
+	limiter := NewBandwidthLimiter(1024)
+	if limiter.Rate() != 1024 {
+		t.Errorf("Rate = %d, want 1024", limiter.Rate())
+	}
+
+	//Unlimited: 100MB is allowed at once
+	limiter.SetRate(0)
+	start := time.Now()
+	if err := limiter.WaitN(context.Background(), 100*1024*1024); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("WaitN without the limit took %s", elapsed)
+	}
+	if limiter.chunkSize() != 0 {
+		t.Errorf("chunkSize without the limit = %d, want 0", limiter.chunkSize())
+	}
+
+	//Limited again: the bytes taken while unlimited are not charged
+	limiter.SetRate(1024 * 1024)
+	start = time.Now()
+	if err := limiter.WaitN(context.Background(), 200*1024); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("200KB at 1MB/s in %s, want about 200ms", elapsed)
+	}
+
+	limiter.SetRate(-1)
+	if limiter.Rate() != 0 {
+		t.Errorf("Rate after SetRate(-1) = %d, want 0", limiter.Rate())
+	}
+}
+
+func TestBandwidthLimiterWaitNCancel(t *testing.T) {
+	limiter := NewBandwidthLimiter(1024)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	//10KB at 1KB/s would take 10 seconds
+	if err := limiter.WaitN(ctx, 10*1024); err != context.DeadlineExceeded {
+		t.Errorf("WaitN = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("WaitN returned after %s", elapsed)
+	}
+
+	//The reader returns the bytes which are read and the error of the context
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	n, err := limiter.Reader(cancelled, bytes.NewReader(make([]byte, 100))).Read(make([]byte, 100))
+	if n != 100 || err != context.Canceled {
+		t.Errorf("Read = %d, %v, want 100, %v", n, err, context.Canceled)
+	}
+}
+
+func TestLimitedReaderChunk(t *testing.T) {
+	tests := []struct {
+		rate int64
+		want int
+	}{
+		//A tenth of the rate
+		{rate: 10 * 1024 * 1024, want: 1024 * 1024},
+		//minLimiterChunk for the low rate
+		{rate: 20 * 1024, want: minLimiterChunk},
+		//The whole buffer without the limit
+		{rate: 0, want: 4 * 1024 * 1024},
+	}
+	for _, test := range tests {
+		limiter := NewBandwidthLimiter(test.rate)
+		//Fill the bucket so the test doesn't wait for the tokens
+		limiter.tokens = float64(test.rate)
+		reader := limiter.Reader(context.Background(), bytes.NewReader(make([]byte, 8*1024*1024)))
+		n, err := reader.Read(make([]byte, 4*1024*1024))
+		if err != nil || n != test.want {
+			t.Errorf("rate %d: Read = %d, %v, want %d", test.rate, n, err, test.want)
+		}
+	}
+
+	var limiter *BandwidthLimiter
+	input := bytes.NewReader(nil)
+	if reader := limiter.Reader(context.Background(), input); reader != io.Reader(input) {
+		t.Error("Reader of the nil limiter must return the reader itself")
+	}
+}
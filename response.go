@@ -1,39 +1,69 @@
-package rthrest
-import "time"
-//RequestTokenResponse : The HTTP response from Authentication/RequestToken request will be decoded to this type by json.Unmarshal
-type RequestTokenResponse struct {
-	//The value in @odata.content field will be decoded to Metadata field
-	Metadata string `json:"@odata.context,omitempty"`
-	Value    string
-}
-
-//IdentifierValidationError : If the indentifier in the request is invalid, the repsonse from extraction (RawExtractionResult) will contain this information.
-type IdentifierValidationError struct {
-	//The value in '@odata.content' field will be decoded to 'Metadata' field
-	Metadata   string `json:"@odata.context,omitempty"`
-	Identifier InstrumentIdentifier
-	Message    string
-}
-
-//RawExtractionResult : The HTTP response from the completed Extractions/ExtractRaw request will be decoded to this type by json.Unmarshal
-type RawExtractionResult struct {
-	//The value in '@odata.content' field will be decoded to this 'Metadata' field
-	Metadata string `json:"@odata.context,omitempty"`
-	//The value in 'JobId' field will be decoded to this 'JobID field
-	JobID                      string `json:"JobId"`
-	Notes                      []string
-	IdentifierValidationErrors []IdentifierValidationError
-}
-//ExtractedFile : Return the data file information from the extractionID
-type ExtractedFile struct {
-	Metadata string `json:"@odata.context,omitempty"`
-	ExtractedFileId string
-	ReportExtractionId string
-	ScheduleId string
-	FileType string
-	ExtractedFileName string
-	LastWriteTimeUtc *time.Time
-	ContentsExists bool
-	Size int64
-	ReceivedDateUtc *time.Time
+package rthrest
+import (
+	"encoding/json"
+	"time"
+)
+//RequestTokenResponse : The HTTP response from Authentication/RequestToken request will be decoded to this type by json.Unmarshal
+type RequestTokenResponse struct {
+	//The value in @odata.content field will be decoded to Metadata field
+	Metadata string `json:"@odata.context,omitempty"`
+	Value    string
+}
+
+//IdentifierValidationError : If the indentifier in the request is invalid, the repsonse from extraction (RawExtractionResult) will contain this information.
+type IdentifierValidationError struct {
+	//The value in '@odata.content' field will be decoded to 'Metadata' field
+	Metadata   string `json:"@odata.context,omitempty"`
+	Identifier InstrumentIdentifier
+	Message    string
+}
+
+//RawExtractionResult : The HTTP response from the completed Extractions/ExtractRaw request will be decoded to this type by json.Unmarshal
+type RawExtractionResult struct {
+	//The value in '@odata.content' field will be decoded to this 'Metadata' field
+	Metadata string `json:"@odata.context,omitempty"`
+	//The value in 'JobId' field will be decoded to this 'JobID field
+	JobID                      string `json:"JobId"`
+	Notes                      []string
+	IdentifierValidationErrors []IdentifierValidationError
+}
+//ExtractedFile : Return the data file information from the extractionID
+type ExtractedFile struct {
+	Metadata string `json:"@odata.context,omitempty"`
+	ExtractedFileId string
+	ReportExtractionId string
+	ScheduleId string
+	FileType string
+	ExtractedFileName string
+	LastWriteTimeUtc *time.Time
+	ContentsExists bool
+	Size int64
+	ReceivedDateUtc *time.Time
+}
+//ReportExtraction : The extraction of a schedule returned by Extractions/ReportExtractions and Extractions/Schedules('id')/CompletedExtractions
+type ReportExtraction struct {
+	Metadata           string `json:"@odata.context,omitempty"`
+	ReportExtractionId string
+	ScheduleId         string
+	Status             string
+	DetailedStatus     string
+	ExtractionDateUtc  *time.Time
+	ScheduleName       string
+	IsTriggered        bool
+	ExtractionStartUtc *time.Time
+	ExtractionEndUtc   *time.Time
+}
+//ODataCollection : One page of the collection returned by the listing endpoints, e.g. Extractions/ReportExtractions.
+//Value contains the JSON array of the entities. NextLink is the URL of the next page. It is empty on the last page
+type ODataCollection struct {
+	Metadata string          `json:"@odata.context,omitempty"`
+	Value    json.RawMessage `json:"value"`
+	NextLink string          `json:"@odata.nextlink,omitempty"`
+}
+//ExtractWithNotesResult : The HTTP response from the completed Extractions/ExtractWithNotes request will be decoded to this type by json.Unmarshal.
+//Each row of Contents is a JSON object keyed by the content field names
+type ExtractWithNotesResult struct {
+	Metadata string `json:"@odata.context,omitempty"`
+	Contents []map[string]json.RawMessage
+	Notes    []string
 }
\ No newline at end of file
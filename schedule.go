@@ -0,0 +1,299 @@
+package rthrest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+)
+
+//The @odata.type of the schedule recurrences and triggers. They are used in ScheduleRecurrence.Metadata and ScheduleTrigger.Metadata
+const (
+	SingleRecurrenceType        = "#DataScope.Select.Api.Extractions.Schedules.SingleRecurrence"
+	DailyRecurrenceType         = "#DataScope.Select.Api.Extractions.Schedules.DailyRecurrence"
+	WeeklyRecurrenceType        = "#DataScope.Select.Api.Extractions.Schedules.WeeklyRecurrence"
+	ImmediateTriggerType        = "#DataScope.Select.Api.Extractions.Schedules.ImmediateTrigger"
+	TimeTriggerType             = "#DataScope.Select.Api.Extractions.Schedules.TimeTrigger"
+	DataAvailabilityTriggerType = "#DataScope.Select.Api.Extractions.Schedules.DataAvailabilityTrigger"
+)
+
+//ReportExtractionCompletedStatus : The Status of ReportExtraction when the extraction is completed
+const ReportExtractionCompletedStatus = "Completed"
+
+//reportExtractionFailedReg : The Status or DetailedStatus of the extraction which ended without the output, e.g. Failed
+var reportExtractionFailedReg = regexp.MustCompile(`(?i)fail|error|cancel|abort|timeout|timedout|expired`)
+
+//InstrumentList : defined type for the instrument list stored on the server. It is used by schedules. This type will be encoded to Json by Marshaller
+type InstrumentList struct {
+	//It uses 'json' metadata to change the fieldname from Metadata to @data.type
+	//It uses user-defined 'odata' metadata to define the default value
+	Metadata string `json:"@odata.type" odata:"#DataScope.Select.Api.Extractions.SubjectLists.InstrumentList"`
+	ListId   string `json:",omitempty"`
+	Name     string
+}
+
+//TimeOfDay : defined type for the time of TimeTrigger
+type TimeOfDay struct {
+	Hour   int
+	Minute int
+}
+
+//ScheduleRecurrence : defined type for the recurrence of the schedule. Metadata is one of SingleRecurrenceType, DailyRecurrenceType and WeeklyRecurrenceType.
+//ExtractionDateTime and IsImmediate are used by SingleRecurrence, IsWeekdaysOnly is used by DailyRecurrence and Days is used by WeeklyRecurrence, e.g. "Monday,Tuesday"
+type ScheduleRecurrence struct {
+	Metadata           string     `json:"@odata.type"`
+	ExtractionDateTime *time.Time `json:",omitempty"`
+	IsImmediate        bool       `json:",omitempty"`
+	IsWeekdaysOnly     bool       `json:",omitempty"`
+	Days               string     `json:",omitempty"`
+}
+
+//ScheduleTrigger : defined type for the trigger of the schedule. Metadata is one of ImmediateTriggerType, TimeTriggerType and DataAvailabilityTriggerType.
+//At is used by TimeTrigger
+type ScheduleTrigger struct {
+	Metadata                string `json:"@odata.type"`
+	LimitReportToTodaysData bool
+	At                      []TimeOfDay `json:",omitempty"`
+}
+
+//Schedule : defined type for the schedule which extracts the report template for the instrument list. This type will be encoded to Json by Marshaller
+type Schedule struct {
+	ScheduleId       string `json:",omitempty"`
+	Name             string
+	OutputFileName   string `json:",omitempty"`
+	TimeZone         string `json:",omitempty"`
+	Recurrence       ScheduleRecurrence
+	Trigger          ScheduleTrigger
+	ListId           string
+	ReportTemplateId string
+}
+
+//NewImmediateSchedule : Create the schedule which is extracted once as soon as it is created
+func NewImmediateSchedule(name string, listID string, reportTemplateID string) *Schedule {
+	return &Schedule{
+		Name:             name,
+		ListId:           listID,
+		ReportTemplateId: reportTemplateID,
+		Recurrence:       ScheduleRecurrence{Metadata: SingleRecurrenceType, IsImmediate: true},
+		Trigger:          ScheduleTrigger{Metadata: ImmediateTriggerType},
+	}
+}
+
+//NewSingleSchedule : Create the schedule which is extracted once at the time
+func NewSingleSchedule(name string, listID string, reportTemplateID string, at time.Time) *Schedule {
+	return &Schedule{
+		Name:             name,
+		ListId:           listID,
+		ReportTemplateId: reportTemplateID,
+		Recurrence:       ScheduleRecurrence{Metadata: SingleRecurrenceType, ExtractionDateTime: &at},
+		Trigger:          ScheduleTrigger{Metadata: ImmediateTriggerType},
+	}
+}
+
+//NewRecurringSchedule : Create the schedule which is extracted on the days at hour:minute in the time zone of the schedule.
+//If days is empty, it is extracted every day
+func NewRecurringSchedule(name string, listID string, reportTemplateID string, days []time.Weekday, hour int, minute int) *Schedule {
+	schedule := &Schedule{
+		Name:             name,
+		ListId:           listID,
+		ReportTemplateId: reportTemplateID,
+		Trigger:          ScheduleTrigger{Metadata: TimeTriggerType, At: []TimeOfDay{{Hour: hour, Minute: minute}}},
+	}
+	if len(days) == 0 {
+		schedule.Recurrence = ScheduleRecurrence{Metadata: DailyRecurrenceType}
+		return schedule
+	}
+	var names []string
+	for _, day := range days {
+		names = append(names, day.String())
+	}
+	schedule.Recurrence = ScheduleRecurrence{Metadata: WeeklyRecurrenceType, Days: strings.Join(names, ",")}
+	return schedule
+}
+
+//NewDataAvailabilitySchedule : Create the schedule which is extracted on the days when the data of the instruments is available.
+//If days is empty, it is extracted every day
+func NewDataAvailabilitySchedule(name string, listID string, reportTemplateID string, days []time.Weekday) *Schedule {
+	schedule := NewRecurringSchedule(name, listID, reportTemplateID, days, 0, 0)
+	schedule.Trigger = ScheduleTrigger{Metadata: DataAvailabilityTriggerType}
+	return schedule
+}
+
+//CreateInstrumentList : Create the instrument list with the identifiers. It returns the ID of the new list
+func (c *Client) CreateInstrumentList(name string, identifiers []InstrumentIdentifier) (string, error) {
+	created := &InstrumentList{}
+	if err := c.postJSON(GetInstrumentListsURL(c.URL), &InstrumentList{Name: name}, created); err != nil {
+		return "", err
+	}
+	if len(identifiers) > 0 {
+		if err := c.AppendInstrumentListIdentifiers(created.ListId, identifiers); err != nil {
+			return created.ListId, err
+		}
+	}
+	return created.ListId, nil
+}
+
+//AppendInstrumentListIdentifiers : Append the identifiers to the instrument list. Duplicate identifiers are not added
+func (c *Client) AppendInstrumentListIdentifiers(listID string, identifiers []InstrumentIdentifier) error {
+	request := struct {
+		Identifiers    []InstrumentIdentifier
+		KeepDuplicates bool
+	}{Identifiers: identifiers}
+	return c.postJSON(GetInstrumentListAppendIdentifiersURL(c.URL, listID), &request, nil)
+}
+
+//ListInstrumentLists : List the instrument lists of the user from Extractions/InstrumentLists. All pages are returned and the query can be nil
+func (c *Client) ListInstrumentLists(query *ODataQuery) ([]InstrumentList, error) {
+	var lists []InstrumentList
+	if err := c.getAllPages(GetInstrumentListsURL(c.URL), query, &lists); err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+//DeleteInstrumentList : Delete the instrument list from the server
+func (c *Client) DeleteInstrumentList(listID string) error {
+	return c.delete(GetInstrumentListURL(c.URL, listID))
+}
+
+//CreateSchedule : Create the schedule by sending POST request to Extractions/Schedules. It returns the ID of the new schedule
+func (c *Client) CreateSchedule(schedule *Schedule) (string, error) {
+	created := &Schedule{}
+	if err := c.postJSON(GetSchedulesURL(c.URL), schedule, created); err != nil {
+		return "", err
+	}
+	schedule.ScheduleId = created.ScheduleId
+	return created.ScheduleId, nil
+}
+
+//GetSchedule : Get the schedule from Extractions/Schedules('id')
+func (c *Client) GetSchedule(scheduleID string) (*Schedule, error) {
+	schedule := &Schedule{}
+	if err := c.getJSON(GetScheduleURL(c.URL, scheduleID), schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+//UpdateSchedule : Replace the schedule on the server by sending PUT request to Extractions/Schedules('id')
+func (c *Client) UpdateSchedule(scheduleID string, schedule *Schedule) error {
+	return c.putJSON(GetScheduleURL(c.URL, scheduleID), schedule)
+}
+
+//DeleteSchedule : Delete the schedule from the server
+func (c *Client) DeleteSchedule(scheduleID string) error {
+	return c.delete(GetScheduleURL(c.URL, scheduleID))
+}
+
+//ListSchedules : List the schedules of the user from Extractions/Schedules. All pages are returned and the query can be nil
+func (c *Client) ListSchedules(query *ODataQuery) ([]Schedule, error) {
+	var schedules []Schedule
+	if err := c.getAllPages(GetSchedulesURL(c.URL), query, &schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+//ScheduleExtractionError : The error returned by WaitForScheduleExtraction when the extraction ends with a failed Status or DetailedStatus
+type ScheduleExtractionError struct {
+	ScheduleID string
+	Extraction *ReportExtraction
+}
+
+//Error : Return the extraction and its status
+func (e *ScheduleExtractionError) Error() string {
+	return fmt.Sprintf("extraction %s of schedule %s failed, Status: %s, DetailedStatus: %s", e.Extraction.ReportExtractionId, e.ScheduleID, e.Extraction.Status, e.Extraction.DetailedStatus)
+}
+
+//ExtractScheduleNow : Start the extraction of the schedule immediately.
+//It returns the ID of the last extraction before this extraction ("" if there is none). It is passed to WaitForScheduleExtraction
+func (c *Client) ExtractScheduleNow(scheduleID string) (string, error) {
+	previousID := ""
+	previous, err := c.GetLastExtraction(scheduleID)
+	if err != nil {
+		return "", err
+	}
+	if previous != nil {
+		previousID = previous.ReportExtractionId
+	}
+	return previousID, c.postJSON(GetScheduleExtractNowURL(c.URL, scheduleID), struct{}{}, nil)
+}
+
+//ListCompletedExtractions : List the completed extractions of the schedule from Extractions/Schedules('id')/CompletedExtractions.
+//All pages are returned and the query can be nil
+func (c *Client) ListCompletedExtractions(scheduleID string, query *ODataQuery) ([]ReportExtraction, error) {
+	var extractions []ReportExtraction
+	if err := c.getAllPages(GetScheduleCompletedExtractionsURL(c.URL, scheduleID), query, &extractions); err != nil {
+		return nil, err
+	}
+	return extractions, nil
+}
+
+//GetLastExtraction : Get the last extraction of the schedule. It returns nil if the schedule is not extracted yet
+func (c *Client) GetLastExtraction(scheduleID string) (*ReportExtraction, error) {
+	extraction := &ReportExtraction{}
+	err := c.getJSON(GetScheduleLastExtractionURL(c.URL, scheduleID), extraction)
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == 404 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if extraction.ReportExtractionId == "" {
+		return nil, nil
+	}
+	return extraction, nil
+}
+
+//WaitForScheduleExtraction : Poll the last extraction of the schedule every pollInterval until an extraction other than previousID ends.
+//previousID is the ID of the last extraction before the trigger, e.g. returned by ExtractScheduleNow. The extractions are matched by ID
+//because the clocks of the client and the server may be different.
+//It returns the completed extraction, ScheduleExtractionError if the extraction failed, or the error of the context
+func (c *Client) WaitForScheduleExtraction(ctx context.Context, scheduleID string, previousID string, pollInterval time.Duration) (*ReportExtraction, error) {
+	if pollInterval <= 0 {
+		pollInterval = 30 * time.Second
+	}
+	for {
+		extraction, err := c.GetLastExtraction(scheduleID)
+		if err != nil {
+			return nil, err
+		}
+		if extraction != nil && extraction.ReportExtractionId != previousID {
+			if reportExtractionFailedReg.MatchString(extraction.Status) || reportExtractionFailedReg.MatchString(extraction.DetailedStatus) {
+				return extraction, &ScheduleExtractionError{ScheduleID: scheduleID, Extraction: extraction}
+			}
+			if extraction.Status == ReportExtractionCompletedStatus {
+				return extraction, nil
+			}
+			log.Printf("Schedule %s: Extraction %s is %s\n", scheduleID, extraction.ReportExtractionId, extraction.Status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for the extraction of schedule %s: %w", scheduleID, ctx.Err())
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+//GetReportExtractionFullFile : Get the data file of the extraction from Extractions/ReportExtractions('id')/FullFile
+func (c *Client) GetReportExtractionFullFile(reportExtractionID string) (*ExtractedFile, error) {
+	extractedFile := &ExtractedFile{}
+	if err := c.getJSON(GetReportExtractionFullFileURL(c.URL, reportExtractionID), extractedFile); err != nil {
+		return nil, err
+	}
+	return extractedFile, nil
+}
+
+//DownloadReportExtraction : Download the data file of the extraction. See DownloadExtractedFile
+func (c *Client) DownloadReportExtraction(reportExtractionID string, outFileName string, numOfConn int) (*ExtractedFile, error) {
+	extractedFile, err := c.GetReportExtractionFullFile(reportExtractionID)
+	if err != nil {
+		return nil, err
+	}
+	return extractedFile, c.DownloadExtractedFile(extractedFile, outFileName, numOfConn)
+}
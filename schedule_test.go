@@ -0,0 +1,102 @@
+package rthrest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+//testScheduleServer : The server of LastExtraction and ScheduleExtractNow. Each LastExtraction request returns the next extraction of the list.
+//The last extraction is returned again when the list ends
+type testScheduleServer struct {
+	*httptest.Server
+	mutex       sync.Mutex
+	extractions []ReportExtraction
+	extractNow  int
+}
+
+func newTestScheduleServer(t *testing.T, extractions ...ReportExtraction) *testScheduleServer {
+	s := &testScheduleServer{extractions: extractions}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "ScheduleExtractNow"):
+			s.extractNow++
+			w.WriteHeader(200)
+		case strings.HasSuffix(r.URL.Path, "LastExtraction"):
+			extraction := s.extractions[0]
+			if len(s.extractions) > 1 {
+				s.extractions = s.extractions[1:]
+			}
+			json.NewEncoder(w).Encode(&extraction)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestWaitForScheduleExtraction(t *testing.T) {
+	//The server clock is behind the client so ExtractionStartUtc is before the trigger time of the client
+	started := time.Now().Add(-time.Hour)
+	previous := ReportExtraction{ReportExtractionId: "1", Status: "Completed", DetailedStatus: "Done"}
+
+	tests := []struct {
+		name      string
+		next      ReportExtraction
+		wantError bool
+	}{
+		{name: "completed", next: ReportExtraction{ReportExtractionId: "2", Status: "Completed", DetailedStatus: "Done", ExtractionStartUtc: &started}},
+		{name: "completed with failure", next: ReportExtraction{ReportExtractionId: "2", Status: "Completed", DetailedStatus: "Failed", ExtractionStartUtc: &started}, wantError: true},
+		{name: "failed", next: ReportExtraction{ReportExtractionId: "2", Status: "Failed", ExtractionStartUtc: &started}, wantError: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			running := ReportExtraction{ReportExtractionId: "2", Status: "InProgress", ExtractionStartUtc: &started}
+			server := newTestScheduleServer(t, previous, previous, running, test.next)
+			client := NewClient(server.Client(), server.URL+"/", false)
+
+			previousID, err := client.ExtractScheduleNow("0x1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if previousID != "1" || server.extractNow != 1 {
+				t.Fatalf("previous ID = %q, ExtractNow requests = %d", previousID, server.extractNow)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			extraction, err := client.WaitForScheduleExtraction(ctx, "0x1", previousID, time.Millisecond)
+			var extractionErr *ScheduleExtractionError
+			if test.wantError != errors.As(err, &extractionErr) {
+				t.Fatalf("error = %v, want ScheduleExtractionError: %v", err, test.wantError)
+			}
+			if !test.wantError && err != nil {
+				t.Fatal(err)
+			}
+			if extraction == nil || extraction.ReportExtractionId != "2" {
+				t.Errorf("extraction = %+v, want 2", extraction)
+			}
+		})
+	}
+}
+
+func TestWaitForScheduleExtractionTimeout(t *testing.T) {
+	//Only the previous extraction is returned
+	server := newTestScheduleServer(t, ReportExtraction{ReportExtractionId: "1", Status: "Completed"})
+	client := NewClient(server.Client(), server.URL+"/", false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.WaitForScheduleExtraction(ctx, "0x1", "1", time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want context.DeadlineExceeded", err)
+	}
+}
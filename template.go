@@ -0,0 +1,136 @@
+package rthrest
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+//The @odata.type of the Tick History report templates. They are used in ReportTemplate.Metadata
+const (
+	TickHistoryMarketDepthReportTemplateType       = "#DataScope.Select.Api.Extractions.ReportTemplates.TickHistoryMarketDepthReportTemplate"
+	TickHistoryTimeAndSalesReportTemplateType      = "#DataScope.Select.Api.Extractions.ReportTemplates.TickHistoryTimeAndSalesReportTemplate"
+	TickHistoryIntradaySummariesReportTemplateType = "#DataScope.Select.Api.Extractions.ReportTemplates.TickHistoryIntradaySummariesReportTemplate"
+	TickHistoryRawReportTemplateType               = "#DataScope.Select.Api.Extractions.ReportTemplates.TickHistoryRawReportTemplate"
+)
+
+//ContentField : defined type for the field in the report template. This type will be encoded to Json by Marshaller
+type ContentField struct {
+	FieldName string
+}
+
+//TickHistoryMarketDepthReportTemplate : defined type for TickHistoryMarketDepthReportTemplate. It stores the field list and the condition of
+//TickHistoryMarketDepthExtractionRequest on the server so it can be reused by schedules. This type will be encoded to Json by Marshaller
+type TickHistoryMarketDepthReportTemplate struct {
+	//It uses 'json' metadata to change the fieldname from Metadata to @data.type
+	//It uses user-defined 'odata' metadata to define the default value
+	Metadata          string `json:"@odata.type" odata:"#DataScope.Select.Api.Extractions.ReportTemplates.TickHistoryMarketDepthReportTemplate"`
+	ReportTemplateId  string `json:",omitempty"`
+	Name              string
+	ShowColumnHeaders bool
+	ContentFields     []ContentField
+	Condition         TickHistoryMarketDepthCondition
+}
+
+//ReportTemplate : defined type for a report template of any type. Condition contains the JSON of the condition of the template type, e.g. TickHistoryTimeAndSalesCondition.
+//Metadata must be set to the template type, e.g. TickHistoryTimeAndSalesReportTemplateType, when the template is created
+type ReportTemplate struct {
+	Metadata          string `json:"@odata.type,omitempty"`
+	ReportTemplateId  string `json:",omitempty"`
+	Name              string
+	ShowColumnHeaders bool
+	ContentFields     []ContentField
+	Condition         json.RawMessage `json:",omitempty"`
+}
+
+//NewTickHistoryMarketDepthReportTemplate : Create the report template from ContentFieldNames and Condition of TickHistoryMarketDepthExtractionRequest.
+//Preview is not a part of the template so it is reset
+func NewTickHistoryMarketDepthReportTemplate(name string, request *TickHistoryMarketDepthExtractionRequest) *TickHistoryMarketDepthReportTemplate {
+	template := &TickHistoryMarketDepthReportTemplate{
+		Name:      name,
+		Condition: request.Condition,
+	}
+	template.Condition.Preview = PreviewModeNoneEnum
+	for _, fieldName := range request.ContentFieldNames {
+		template.ContentFields = append(template.ContentFields, ContentField{FieldName: fieldName})
+	}
+	return template
+}
+
+//ContentFieldNames : Return the field names of the template. They can be used as ContentFieldNames of the extraction request
+func (t *TickHistoryMarketDepthReportTemplate) ContentFieldNames() []string {
+	var names []string
+	for _, field := range t.ContentFields {
+		names = append(names, field.FieldName)
+	}
+	return names
+}
+
+//CreateReportTemplate : Create the report template on the server by sending POST request to Extractions/ReportTemplates.
+//template can be *TickHistoryMarketDepthReportTemplate or *ReportTemplate. It returns the ID of the new template
+func (c *Client) CreateReportTemplate(template interface{}) (string, error) {
+	created := &ReportTemplate{}
+	if err := c.postJSON(GetReportTemplatesURL(c.URL), template, created); err != nil {
+		return "", err
+	}
+	if t, ok := template.(*TickHistoryMarketDepthReportTemplate); ok {
+		t.ReportTemplateId = created.ReportTemplateId
+	}
+	if t, ok := template.(*ReportTemplate); ok {
+		t.ReportTemplateId = created.ReportTemplateId
+	}
+	return created.ReportTemplateId, nil
+}
+
+//GetReportTemplate : Get the report template of any type from Extractions/ReportTemplates('id')
+func (c *Client) GetReportTemplate(reportTemplateID string) (*ReportTemplate, error) {
+	template := &ReportTemplate{}
+	if err := c.getJSON(GetReportTemplateURL(c.URL, reportTemplateID), template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+//GetTickHistoryMarketDepthReportTemplate : Get the market depth report template from Extractions/ReportTemplates('id')
+func (c *Client) GetTickHistoryMarketDepthReportTemplate(reportTemplateID string) (*TickHistoryMarketDepthReportTemplate, error) {
+	template := &TickHistoryMarketDepthReportTemplate{}
+	if err := c.getJSON(GetReportTemplateURL(c.URL, reportTemplateID), template); err != nil {
+		return nil, err
+	}
+	if template.Metadata != "" && template.Metadata != TickHistoryMarketDepthReportTemplateType {
+		return nil, fmt.Errorf("report template %s is %s", reportTemplateID, template.Metadata)
+	}
+	return template, nil
+}
+
+//UpdateReportTemplate : Replace the report template on the server by sending PUT request to Extractions/ReportTemplates('id')
+func (c *Client) UpdateReportTemplate(reportTemplateID string, template interface{}) error {
+	return c.putJSON(GetReportTemplateURL(c.URL, reportTemplateID), template)
+}
+
+//DeleteReportTemplate : Delete the report template from the server
+func (c *Client) DeleteReportTemplate(reportTemplateID string) error {
+	return c.delete(GetReportTemplateURL(c.URL, reportTemplateID))
+}
+
+//ListReportTemplates : List the report templates of the user from Extractions/ReportTemplates. All pages are returned and the query can be nil
+func (c *Client) ListReportTemplates(query *ODataQuery) ([]ReportTemplate, error) {
+	var templates []ReportTemplate
+	if err := c.getAllPages(GetReportTemplatesURL(c.URL), query, &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+//GetReportTemplateByName : Find the report template by name. It returns nil if there is no template with the name
+func (c *Client) GetReportTemplateByName(name string) (*ReportTemplate, error) {
+	templates, err := c.ListReportTemplates(NewODataQuery().WithFilter("Name eq " + ODataString(name)))
+	if err != nil {
+		return nil, err
+	}
+	for i := range templates {
+		if templates[i].Name == name {
+			return &templates[i], nil
+		}
+	}
+	return nil, nil
+}
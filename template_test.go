@@ -0,0 +1,162 @@
+package rthrest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+//testTemplateServer : The server of Extractions/ReportTemplates which keeps the templates in memory by ID
+type testTemplateServer struct {
+	*httptest.Server
+	mutex     sync.Mutex
+	templates map[string]map[string]interface{}
+	filters   []string
+}
+
+func newTestTemplateServer(t *testing.T) *testTemplateServer {
+	s := &testTemplateServer{templates: make(map[string]map[string]interface{})}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		id := ""
+		if i := strings.Index(r.URL.Path, "ReportTemplates('"); i >= 0 {
+			id = strings.TrimSuffix(r.URL.Path[i+len("ReportTemplates('"):], "')")
+		}
+
+		var body map[string]interface{}
+		if r.Method == "POST" || r.Method == "PUT" {
+			data, _ := ioutil.ReadAll(r.Body)
+			if err := json.Unmarshal(data, &body); err != nil {
+				http.Error(w, err.Error(), 400)
+				return
+			}
+		}
+		switch {
+		case r.Method == "POST" && id == "":
+			id = "0x0" + string(rune('1'+len(s.templates)))
+			body["ReportTemplateId"] = id
+			s.templates[id] = body
+			w.WriteHeader(201)
+			json.NewEncoder(w).Encode(body)
+		case r.Method == "GET" && id == "":
+			s.filters = append(s.filters, r.URL.Query().Get("$filter"))
+			var value []map[string]interface{}
+			for _, template := range s.templates {
+				value = append(value, template)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"value": value})
+		case s.templates[id] == nil:
+			http.Error(w, "Not Found", 404)
+		case r.Method == "GET":
+			json.NewEncoder(w).Encode(s.templates[id])
+		case r.Method == "PUT":
+			s.templates[id] = body
+			w.WriteHeader(204)
+		case r.Method == "DELETE":
+			delete(s.templates, id)
+			w.WriteHeader(204)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestReportTemplateRoundTrip(t *testing.T) {
+	server := newTestTemplateServer(t)
+	client := NewClient(server.Client(), server.URL+"/", false)
+
+	request := &TickHistoryMarketDepthExtractionRequest{ContentFieldNames: []string{"Ask Price", "Bid Price"}}
+	request.Condition.View = ViewOptionsNormalizedLL2Enum
+	request.Condition.NumberOfLevels = 5
+	request.Condition.Preview = PreviewModeContentEnum
+	template := NewTickHistoryMarketDepthReportTemplate("O'Neil depth", request)
+
+	id, err := client.CreateReportTemplate(template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == "" || template.ReportTemplateId != id {
+		t.Errorf("ID = %q, ReportTemplateId = %q", id, template.ReportTemplateId)
+	}
+	//The template type is sent in @odata.type and Preview is not a part of the template
+	sent := server.templates[id]
+	if sent["@odata.type"] != TickHistoryMarketDepthReportTemplateType {
+		t.Errorf("@odata.type = %v, want %s", sent["@odata.type"], TickHistoryMarketDepthReportTemplateType)
+	}
+	if condition, _ := sent["Condition"].(map[string]interface{}); condition == nil || condition["Preview"] != "None" || condition["View"] != "NormalizedLL2" {
+		t.Errorf("Condition = %v", sent["Condition"])
+	}
+
+	got, err := client.GetTickHistoryMarketDepthReportTemplate(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "O'Neil depth" || got.Condition.NumberOfLevels != 5 || strings.Join(got.ContentFieldNames(), ",") != "Ask Price,Bid Price" {
+		t.Errorf("template = %+v", got)
+	}
+
+	got.Name = "renamed"
+	if err = client.UpdateReportTemplate(id, got); err != nil {
+		t.Fatal(err)
+	}
+	generic, err := client.GetReportTemplate(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if generic.Name != "renamed" || generic.Metadata != TickHistoryMarketDepthReportTemplateType || len(generic.Condition) == 0 {
+		t.Errorf("template = %+v", generic)
+	}
+
+	if err = client.DeleteReportTemplate(id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.GetReportTemplate(id); err == nil {
+		t.Error("GetReportTemplate of the deleted template: want error")
+	} else if statusErr, ok := err.(*HTTPStatusError); !ok || statusErr.StatusCode != 404 {
+		t.Errorf("GetReportTemplate of the deleted template = %v, want 404", err)
+	}
+}
+
+func TestGetTickHistoryMarketDepthReportTemplateType(t *testing.T) {
+	server := newTestTemplateServer(t)
+	client := NewClient(server.Client(), server.URL+"/", false)
+	id, err := client.CreateReportTemplate(&ReportTemplate{Metadata: TickHistoryTimeAndSalesReportTemplateType, Name: "tas"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.GetTickHistoryMarketDepthReportTemplate(id); err == nil {
+		t.Error("GetTickHistoryMarketDepthReportTemplate of the time and sales template: want error")
+	}
+}
+
+func TestGetReportTemplateByName(t *testing.T) {
+	server := newTestTemplateServer(t)
+	client := NewClient(server.Client(), server.URL+"/", false)
+	for _, name := range []string{"depth", "O'Neil depth"} {
+		if _, err := client.CreateReportTemplate(&ReportTemplate{Metadata: TickHistoryMarketDepthReportTemplateType, Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := client.GetReportTemplateByName("O'Neil depth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found == nil || found.Name != "O'Neil depth" || found.ReportTemplateId == "" {
+		t.Errorf("GetReportTemplateByName = %+v", found)
+	}
+
+	//The server returns the other templates but none has the name
+	missing, err := client.GetReportTemplateByName("missing")
+	if err != nil || missing != nil {
+		t.Errorf("GetReportTemplateByName(missing) = %+v, %v, want nil", missing, err)
+	}
+	if len(server.filters) != 2 || server.filters[0] != "Name eq 'O''Neil depth'" || server.filters[1] != "Name eq 'missing'" {
+		t.Errorf("$filter = %q", server.filters)
+	}
+}
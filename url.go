@@ -1,14 +1,83 @@
-package rthrest
-
-func GetRequestTokenURL(rthapiurl string)(string){
-	return rthapiurl+"Authentication/RequestToken"
-}
-func GetExtractRawURL(rthapiurl string)(string){
-	return rthapiurl+"Extractions/ExtractRaw"
-}
-func GetReportExtractionFullFileURL(rthapiurl string, extractionId string)(string){
-	return rthapiurl + "Extractions/ReportExtractions('" + extractionId + "')/FullFile"
-}
-func GetRawExtractionResultGetDefaultStreamURL(rthapiurl string, jobId string)(string){
-	return  rthapiurl + "Extractions/RawExtractionResults('" + jobId + "')" + "/$value"
+package rthrest
+
+func GetRequestTokenURL(rthapiurl string)(string){
+	return rthapiurl+"Authentication/RequestToken"
+}
+func GetExtractRawURL(rthapiurl string)(string){
+	return rthapiurl+"Extractions/ExtractRaw"
+}
+func GetReportExtractionFullFileURL(rthapiurl string, extractionId string)(string){
+	return rthapiurl + "Extractions/ReportExtractions('" + extractionId + "')/FullFile"
+}
+func GetRawExtractionResultGetDefaultStreamURL(rthapiurl string, jobId string)(string){
+	return  rthapiurl + "Extractions/RawExtractionResults('" + jobId + "')" + "/$value"
+}
+func GetReportExtractionFilesURL(rthapiurl string, extractionId string)(string){
+	return rthapiurl + "Extractions/ReportExtractions('" + extractionId + "')/Files"
+}
+func GetExtractedFileURL(rthapiurl string, extractedFileId string)(string){
+	return rthapiurl + "Extractions/ExtractedFiles('" + extractedFileId + "')"
+}
+func GetExtractedFileGetDefaultStreamURL(rthapiurl string, extractedFileId string)(string){
+	return rthapiurl + "Extractions/ExtractedFiles('" + extractedFileId + "')" + "/$value"
+}
+func GetReportTemplatesURL(rthapiurl string)(string){
+	return rthapiurl + "Extractions/ReportTemplates"
+}
+func GetReportTemplateURL(rthapiurl string, reportTemplateId string)(string){
+	return rthapiurl + "Extractions/ReportTemplates('" + reportTemplateId + "')"
+}
+func GetInstrumentListsURL(rthapiurl string)(string){
+	return rthapiurl + "Extractions/InstrumentLists"
+}
+func GetInstrumentListURL(rthapiurl string, listId string)(string){
+	return rthapiurl + "Extractions/InstrumentLists('" + listId + "')"
+}
+func GetInstrumentListAppendIdentifiersURL(rthapiurl string, listId string)(string){
+	return rthapiurl + "Extractions/InstrumentLists('" + listId + "')/DataScope.Select.Api.Extractions.InstrumentListAppendIdentifiers"
+}
+func GetSchedulesURL(rthapiurl string)(string){
+	return rthapiurl + "Extractions/Schedules"
+}
+func GetScheduleURL(rthapiurl string, scheduleId string)(string){
+	return rthapiurl + "Extractions/Schedules('" + scheduleId + "')"
+}
+func GetScheduleExtractNowURL(rthapiurl string, scheduleId string)(string){
+	return rthapiurl + "Extractions/Schedules('" + scheduleId + "')/DataScope.Select.Api.Extractions.ScheduleExtractNow"
+}
+func GetScheduleCompletedExtractionsURL(rthapiurl string, scheduleId string)(string){
+	return rthapiurl + "Extractions/Schedules('" + scheduleId + "')/CompletedExtractions"
+}
+func GetScheduleLastExtractionURL(rthapiurl string, scheduleId string)(string){
+	return rthapiurl + "Extractions/Schedules('" + scheduleId + "')/LastExtraction"
+}
+func GetReportExtractionURL(rthapiurl string, extractionId string)(string){
+	return rthapiurl + "Extractions/ReportExtractions('" + extractionId + "')"
+}
+func GetUserPackagesURL(rthapiurl string)(string){
+	return rthapiurl + "StandardExtractions/UserPackages"
+}
+func GetUserPackageDeliveriesByPackageIdURL(rthapiurl string, packageId string)(string){
+	return rthapiurl + "StandardExtractions/UserPackageDeliveryGetUserPackageDeliveriesByPackageId(PackageId='" + packageId + "')"
+}
+func GetUserPackageDeliveriesByDateRangeURL(rthapiurl string, subscriptionId string, fromDate string, toDate string)(string){
+	return rthapiurl + "StandardExtractions/UserPackageDeliveryGetUserPackageDeliveriesByDateRange(SubscriptionId='" + subscriptionId + "',FromDate=" + fromDate + ",ToDate=" + toDate + ")"
+}
+func GetUserPackageDeliveryGetDefaultStreamURL(rthapiurl string, packageDeliveryId string)(string){
+	return rthapiurl + "StandardExtractions/UserPackageDeliveries('" + packageDeliveryId + "')" + "/$value"
+}
+func GetQuotaInformationURL(rthapiurl string)(string){
+	return rthapiurl + "Quota/GetQuotaInformation"
+}
+func GetRawExtractionResultsURL(rthapiurl string)(string){
+	return rthapiurl + "Extractions/RawExtractionResults"
+}
+func GetRawExtractionResultURL(rthapiurl string, jobId string)(string){
+	return rthapiurl + "Extractions/RawExtractionResults('" + jobId + "')"
+}
+func GetReportExtractionsURL(rthapiurl string)(string){
+	return rthapiurl + "Extractions/ReportExtractions"
+}
+func GetExtractWithNotesURL(rthapiurl string)(string){
+	return rthapiurl + "Extractions/ExtractWithNotes"
 }
\ No newline at end of file
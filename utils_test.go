@@ -0,0 +1,41 @@
+package rthrest
+
+import "testing"
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		header  string
+		start   int64
+		end     int64
+		total   int64
+		invalid bool
+	}{
+		{header: "bytes 0-499/1234", start: 0, end: 499, total: 1234},
+		{header: "bytes 500-1233/1234", start: 500, end: 1233, total: 1234},
+		{header: "bytes 500-1233/*", start: 500, end: 1233, total: -1},
+		{header: "bytes */1234", start: -1, end: -1, total: 1234},
+		{header: "", invalid: true},
+		{header: "items 0-1/2", invalid: true},
+		{header: "bytes 0-499", invalid: true},
+		{header: "bytes 0/1234", invalid: true},
+		{header: "bytes a-499/1234", invalid: true},
+		{header: "bytes 0-b/1234", invalid: true},
+		{header: "bytes 0-499/c", invalid: true},
+	}
+	for _, test := range tests {
+		start, end, total, err := parseContentRange(test.header)
+		if test.invalid {
+			if err == nil {
+				t.Errorf("%q: no error", test.header)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %s", test.header, err.Error())
+			continue
+		}
+		if start != test.start || end != test.end || total != test.total {
+			t.Errorf("%q = %d, %d, %d, want %d, %d, %d", test.header, start, end, total, test.start, test.end, test.total)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package rthrest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//IdentifierValidationPolicy : This is an enumeration for the way IdentifierValidationErrors in RawExtractionResult are handled
+type IdentifierValidationPolicy int
+
+//Available Enumerations for IdentifierValidationPolicy
+const (
+	ValidationPolicyFailEnum IdentifierValidationPolicy = iota
+	ValidationPolicyWarnEnum
+	ValidationPolicyIgnoreEnum
+	ValidationPolicyWriteReportEnum
+)
+
+//Enumeration String of identifierValidationPolicy enumeration used by String and ParseIdentifierValidationPolicy
+var identifierValidationPolicy = [...]string{
+	"fail",
+	"warn",
+	"ignore",
+	"report",
+}
+
+//String : It uses identifierValidationPolicy string array variable to convert int (enum) to string
+func (p IdentifierValidationPolicy) String() string {
+	return identifierValidationPolicy[p]
+}
+
+//ParseIdentifierValidationPolicy : Convert the policy name (fail, warn, ignore, report) to IdentifierValidationPolicy
+func ParseIdentifierValidationPolicy(name string) (IdentifierValidationPolicy, error) {
+	for i, v := range identifierValidationPolicy {
+		if strings.EqualFold(v, name) {
+			return IdentifierValidationPolicy(i), nil
+		}
+	}
+	return ValidationPolicyFailEnum, fmt.Errorf("unknown identifier validation policy: %q", name)
+}
+
+//IdentifierValidationOptions : defined type for the policy applied by CheckIdentifierValidationErrors
+type IdentifierValidationOptions struct {
+	Policy IdentifierValidationPolicy
+	//MaxRejectionRate is the highest accepted ratio (0.0 - 1.0) of rejected instruments.
+	//With ValidationPolicyFailEnum, the zero value means that any rejected instrument fails the extraction.
+	//With ValidationPolicyWarnEnum, ValidationPolicyIgnoreEnum and ValidationPolicyWriteReportEnum, the zero value disables the check
+	MaxRejectionRate float64
+}
+
+//Error : IdentifierValidationError can be used as an error value
+func (e IdentifierValidationError) Error() string {
+	return fmt.Sprintf("%s (%s): %s", e.Identifier.Identifier, e.Identifier.IdentifierType, e.Message)
+}
+
+//IdentifierRejectionError : The error returned by CheckIdentifierValidationErrors when too many instruments are rejected
+type IdentifierRejectionError struct {
+	JobID    string
+	Rejected []IdentifierValidationError
+	Total    int
+	Rate     float64
+}
+
+//Error : Return the summary of rejected instruments
+func (e *IdentifierRejectionError) Error() string {
+	return fmt.Sprintf("job %s: %d of %d instruments (%.1f%%) were rejected by identifier validation", e.JobID, len(e.Rejected), e.Total, e.Rate*100)
+}
+
+//RejectedInstrumentsFileName : Return the name of the rejected instruments report written next to the output file.
+//For example, output_123.csv.gz becomes output_123.rejected.csv
+func RejectedInstrumentsFileName(outFileName string) string {
+	dir, base := filepath.Split(outFileName)
+	if i := strings.Index(base, "."); i > 0 {
+		base = base[:i]
+	}
+	return filepath.Join(dir, base+".rejected.csv")
+}
+
+//WriteRejectedInstruments : Write IdentifierValidationErrors to a CSV file with Identifier, IdentifierType and Message columns
+func WriteRejectedInstruments(fileName string, rejected []IdentifierValidationError) error {
+	out, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(out)
+	writer.Write([]string{"Identifier", "IdentifierType", "Message"})
+	for _, e := range rejected {
+		writer.Write([]string{e.Identifier.Identifier, e.Identifier.IdentifierType, e.Message})
+	}
+	writer.Flush()
+	err = writer.Error()
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+//CheckIdentifierValidationErrors : Apply the policy to IdentifierValidationErrors in the extraction result.
+//numOfInstruments is the number of instruments in the request and it is used to calculate the rejection rate.
+//outFileName is the name of the output file. The report of rejected instruments is written next to it.
+//ValidationPolicyIgnoreEnum doesn't log or write the rejected instruments, but MaxRejectionRate is still checked if it is set.
+//It returns *IdentifierRejectionError when the rejection rate exceeds options.MaxRejectionRate
+func CheckIdentifierValidationErrors(result *RawExtractionResult, numOfInstruments int, options IdentifierValidationOptions, outFileName string) error {
+	rejected := result.IdentifierValidationErrors
+	if len(rejected) == 0 {
+		return nil
+	}
+
+	var rate float64
+	if numOfInstruments > 0 {
+		rate = float64(len(rejected)) / float64(numOfInstruments)
+	} else {
+		rate = 1
+	}
+
+	switch options.Policy {
+	case ValidationPolicyFailEnum, ValidationPolicyWarnEnum:
+		for _, e := range rejected {
+			log.Printf("Identifier Validation: %s\n", e.Error())
+		}
+	case ValidationPolicyWriteReportEnum:
+		reportFileName := RejectedInstrumentsFileName(outFileName)
+		if err := WriteRejectedInstruments(reportFileName, rejected); err != nil {
+			return err
+		}
+		log.Printf("Identifier Validation: %d rejected instruments are written to %s\n", len(rejected), reportFileName)
+	}
+
+	if options.Policy == ValidationPolicyFailEnum || options.MaxRejectionRate > 0 {
+		if rate > options.MaxRejectionRate {
+			return &IdentifierRejectionError{
+				JobID:    result.JobID,
+				Rejected: rejected,
+				Total:    numOfInstruments,
+				Rate:     rate,
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package rthrest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+//testValidationResult : Return the extraction result with the number of rejected instruments
+func testValidationResult(rejected int) *RawExtractionResult {
+	result := &RawExtractionResult{JobID: "0x1"}
+	for i := 0; i < rejected; i++ {
+		result.IdentifierValidationErrors = append(result.IdentifierValidationErrors, IdentifierValidationError{
+			Identifier: InstrumentIdentifier{Identifier: "BAD" + string(rune('A'+i)) + ".L", IdentifierType: "Ric"},
+			Message:    "Not found",
+		})
+	}
+	return result
+}
+
+func TestCheckIdentifierValidationErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   IdentifierValidationPolicy
+		maxRate  float64
+		rejected int
+		total    int
+		fail     bool
+		report   bool
+	}{
+		{name: "fail without rejected", policy: ValidationPolicyFailEnum, total: 4},
+		{name: "fail with any rejected", policy: ValidationPolicyFailEnum, rejected: 1, total: 4, fail: true},
+		{name: "fail at the threshold", policy: ValidationPolicyFailEnum, maxRate: 0.25, rejected: 1, total: 4},
+		{name: "fail above the threshold", policy: ValidationPolicyFailEnum, maxRate: 0.25, rejected: 2, total: 4, fail: true},
+		{name: "fail without instruments", policy: ValidationPolicyFailEnum, maxRate: 0.5, rejected: 1, fail: true},
+		{name: "warn without threshold", policy: ValidationPolicyWarnEnum, rejected: 4, total: 4},
+		{name: "warn at the threshold", policy: ValidationPolicyWarnEnum, maxRate: 0.5, rejected: 2, total: 4},
+		{name: "warn above the threshold", policy: ValidationPolicyWarnEnum, maxRate: 0.5, rejected: 3, total: 4, fail: true},
+		{name: "ignore without threshold", policy: ValidationPolicyIgnoreEnum, rejected: 4, total: 4},
+		{name: "ignore at the threshold", policy: ValidationPolicyIgnoreEnum, maxRate: 0.5, rejected: 2, total: 4},
+		{name: "ignore above the threshold", policy: ValidationPolicyIgnoreEnum, maxRate: 0.5, rejected: 3, total: 4, fail: true},
+		{name: "report without threshold", policy: ValidationPolicyWriteReportEnum, rejected: 2, total: 4, report: true},
+		{name: "report above the threshold", policy: ValidationPolicyWriteReportEnum, maxRate: 0.25, rejected: 2, total: 4, fail: true, report: true},
+	}
+	for _, test := range tests {
+		outFileName := filepath.Join(t.TempDir(), "output_0x1.csv.gz")
+		result := testValidationResult(test.rejected)
+		options := IdentifierValidationOptions{Policy: test.policy, MaxRejectionRate: test.maxRate}
+
+		err := CheckIdentifierValidationErrors(result, test.total, options, outFileName)
+		if test.fail {
+			rejection, ok := err.(*IdentifierRejectionError)
+			if !ok {
+				t.Errorf("%s: error = %v, want IdentifierRejectionError", test.name, err)
+			} else if rejection.JobID != "0x1" || len(rejection.Rejected) != test.rejected || rejection.Total != test.total {
+				t.Errorf("%s: error = %+v", test.name, rejection)
+			}
+		} else if err != nil {
+			t.Errorf("%s: %s", test.name, err.Error())
+		}
+
+		_, statErr := os.Stat(RejectedInstrumentsFileName(outFileName))
+		if test.report != (statErr == nil) {
+			t.Errorf("%s: report written = %v, want %v", test.name, statErr == nil, test.report)
+		}
+	}
+}
+
+func TestWriteRejectedInstruments(t *testing.T) {
+	fileName := RejectedInstrumentsFileName(filepath.Join(t.TempDir(), "output_0x1.csv.gz"))
+	if filepath.Base(fileName) != "output_0x1.rejected.csv" {
+		t.Errorf("RejectedInstrumentsFileName = %s", fileName)
+	}
+	rejected := testValidationResult(1).IdentifierValidationErrors
+	rejected = append(rejected, IdentifierValidationError{
+		Identifier: InstrumentIdentifier{Identifier: "X,Y", IdentifierType: "Ric"},
+		Message:    "Invalid, identifier",
+	})
+	if err := WriteRejectedInstruments(fileName, rejected); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Identifier,IdentifierType,Message\nBADA.L,Ric,Not found\n\"X,Y\",Ric,\"Invalid, identifier\"\n"
+	if string(data) != want {
+		t.Errorf("report =\n%s\nwant\n%s", data, want)
+	}
+
+	if err := WriteRejectedInstruments(filepath.Join(fileName, "missing", "report.csv"), rejected); err == nil {
+		t.Error("WriteRejectedInstruments to a missing directory: want error")
+	}
+}
+
+func TestParseIdentifierValidationPolicy(t *testing.T) {
+	for i, name := range identifierValidationPolicy {
+		policy, err := ParseIdentifierValidationPolicy(name)
+		if err != nil || policy != IdentifierValidationPolicy(i) || policy.String() != name {
+			t.Errorf("ParseIdentifierValidationPolicy(%q) = %v, %v", name, policy, err)
+		}
+	}
+	if _, err := ParseIdentifierValidationPolicy("drop"); err == nil {
+		t.Error("ParseIdentifierValidationPolicy(\"drop\"): want error")
+	}
+}
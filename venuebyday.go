@@ -0,0 +1,189 @@
+package rthrest
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+//UserPackage : defined type for the Venue by Day package subscribed by the user. It is returned by StandardExtractions/UserPackages
+type UserPackage struct {
+	UserPackageId    string
+	PackageId        string
+	PackageName      string
+	SubscriptionId   string
+	SubscriptionName string
+}
+
+//UserPackageDelivery : defined type for the file of the Venue by Day package. The name of the file starts with the venue code,
+//e.g. ASX-2019-09-18-NORMALIZEDMP-Data-1-of-1.csv.gz
+type UserPackageDelivery struct {
+	PackageDeliveryId string
+	UserPackageId     string
+	SubscriptionId    string
+	Name              string
+	ReleaseDateTime   *time.Time
+	FileSizeBytes     int64
+	Frequency         string
+	ContentMd5        string
+}
+
+//UserPackageDeliveryFilter : The filter of the deliveries. Venues are the venue codes, e.g. ASX. Empty Venues matches all venues.
+//From and To are the range of ReleaseDateTime. The zero time is not used
+type UserPackageDeliveryFilter struct {
+	Venues []string
+	From   time.Time
+	To     time.Time
+}
+
+//Venue : Return the venue code from the file name, e.g. ASX from ASX-2019-09-18-NORMALIZEDMP-Data-1-of-1.csv.gz
+func (d *UserPackageDelivery) Venue() string {
+	if i := strings.Index(d.Name, "-"); i > 0 {
+		return d.Name[:i]
+	}
+	return ""
+}
+
+//Match : Return true if the delivery matches the venues and the date range of the filter
+func (f *UserPackageDeliveryFilter) Match(delivery *UserPackageDelivery) bool {
+	if len(f.Venues) > 0 {
+		found := false
+		for _, venue := range f.Venues {
+			if strings.EqualFold(venue, delivery.Venue()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if delivery.ReleaseDateTime != nil {
+		if !f.From.IsZero() && delivery.ReleaseDateTime.Before(f.From) {
+			return false
+		}
+		if !f.To.IsZero() && delivery.ReleaseDateTime.After(f.To) {
+			return false
+		}
+	}
+	return true
+}
+
+//FilterUserPackageDeliveries : Return the deliveries which match the filter
+func FilterUserPackageDeliveries(deliveries []UserPackageDelivery, filter UserPackageDeliveryFilter) []UserPackageDelivery {
+	var found []UserPackageDelivery
+	for i := range deliveries {
+		if filter.Match(&deliveries[i]) {
+			found = append(found, deliveries[i])
+		}
+	}
+	return found
+}
+
+//ListUserPackages : List the Venue by Day packages subscribed by the user from StandardExtractions/UserPackages.
+//All pages are returned and the query can be nil
+func (c *Client) ListUserPackages(query *ODataQuery) ([]UserPackage, error) {
+	var packages []UserPackage
+	if err := c.getAllPages(GetUserPackagesURL(c.URL), query, &packages); err != nil {
+		return nil, err
+	}
+	return packages, nil
+}
+
+//ListUserPackageDeliveries : List all deliveries of the package. All pages are returned and the query can be nil
+func (c *Client) ListUserPackageDeliveries(packageID string, query *ODataQuery) ([]UserPackageDelivery, error) {
+	var deliveries []UserPackageDelivery
+	if err := c.getAllPages(GetUserPackageDeliveriesByPackageIdURL(c.URL, packageID), query, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+//ListUserPackageDeliveriesByDateRange : List all deliveries of the subscription which are released from fromDate to toDate.
+//All pages are returned and the query can be nil
+func (c *Client) ListUserPackageDeliveriesByDateRange(subscriptionID string, fromDate time.Time, toDate time.Time, query *ODataQuery) ([]UserPackageDelivery, error) {
+	url := GetUserPackageDeliveriesByDateRangeURL(c.URL, subscriptionID, fromDate.UTC().Format(time.RFC3339), toDate.UTC().Format(time.RFC3339))
+	var deliveries []UserPackageDelivery
+	if err := c.getAllPages(url, query, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+//DownloadUserPackageDelivery : Download the delivery to outFileName. If outFileName is empty, Name of the delivery is used.
+//numOfConn is the number of connections. If it is 0, the number of connections is chosen by AutoDownload.
+//The file is verified against FileSizeBytes and ContentMd5
+func (c *Client) DownloadUserPackageDelivery(delivery *UserPackageDelivery, outFileName string, numOfConn int) error {
+	if outFileName == "" {
+		outFileName = delivery.Name
+	}
+	downloader := c.NewDownloader()
+	url := GetUserPackageDeliveryGetDefaultStreamURL(c.URL, delivery.PackageDeliveryId)
+
+	var err error
+	switch {
+	case numOfConn == 0:
+		err = downloader.AutoDownload(url, outFileName)
+	case numOfConn > 1 && delivery.FileSizeBytes > 0:
+		err = downloader.ConcurrentDownload(url, outFileName, numOfConn, delivery.FileSizeBytes)
+	default:
+		err = downloader.Download(url, outFileName, delivery.FileSizeBytes)
+	}
+	if err != nil || delivery.ContentMd5 == "" {
+		return err
+	}
+
+	//The gzip stream and the size are verified by the downloader
+	err = VerifyFile(outFileName, 0, delivery.ContentMd5, false)
+	if integrityErr, ok := err.(*IntegrityError); ok {
+		return handleCorruptFile(outFileName, outFileName, downloader.CorruptFileAction, integrityErr)
+	}
+	return err
+}
+
+//DownloadUserPackageDeliveries : Download the deliveries to outDir. numOfFiles files are downloaded at the same time
+//and each file is downloaded with numOfConn connections. See DownloadUserPackageDelivery.
+//All deliveries are tried and the error of the first failed delivery is returned
+func (c *Client) DownloadUserPackageDeliveries(deliveries []UserPackageDelivery, outDir string, numOfConn int, numOfFiles int) error {
+	if numOfFiles < 1 {
+		numOfFiles = 1
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var firstErr error
+	failed := 0
+	jobs := make(chan *UserPackageDelivery)
+	for i := 0; i < numOfFiles; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for delivery := range jobs {
+				outFileName := filepath.Join(outDir, delivery.Name)
+				log.Printf("Download %s: %s, Size: %d\n", delivery.Venue(), outFileName, delivery.FileSizeBytes)
+				if err := c.DownloadUserPackageDelivery(delivery, outFileName, numOfConn); err != nil {
+					log.Printf("Download %s failed: %s\n", delivery.Name, err.Error())
+					mutex.Lock()
+					failed++
+					if firstErr == nil {
+						firstErr = err
+					}
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range deliveries {
+		jobs <- &deliveries[i]
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return fmt.Errorf("%d of %d deliveries failed: %w", failed, len(deliveries), firstErr)
+	}
+	return nil
+}
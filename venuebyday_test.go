@@ -0,0 +1,132 @@
+package rthrest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUserPackageDeliveryVenue(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "ASX-2019-09-18-NORMALIZEDMP-Data-1-of-1.csv.gz", want: "ASX"},
+		{name: "NSQ-2019-09-18-MARKETPRICE-Report-1-of-1.csv.gz", want: "NSQ"},
+		{name: "-2019-09-18.csv.gz"},
+		{name: "README.txt"},
+		{name: ""},
+	}
+	for _, test := range tests {
+		delivery := UserPackageDelivery{Name: test.name}
+		if got := delivery.Venue(); got != test.want {
+			t.Errorf("Venue(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestFilterUserPackageDeliveries(t *testing.T) {
+	day := func(value string) *time.Time {
+		released := testTime(t, value)
+		return &released
+	}
+	deliveries := []UserPackageDelivery{
+		{Name: "ASX-2019-09-17-NORMALIZEDMP-Data-1-of-1.csv.gz", ReleaseDateTime: day("2019-09-17T23:00:00Z")},
+		{Name: "ASX-2019-09-18-NORMALIZEDMP-Data-1-of-1.csv.gz", ReleaseDateTime: day("2019-09-18T23:00:00Z")},
+		{Name: "NSQ-2019-09-18-NORMALIZEDMP-Data-1-of-1.csv.gz", ReleaseDateTime: day("2019-09-18T23:00:00Z")},
+		{Name: "LSE-2019-09-19-NORMALIZEDMP-Data-1-of-1.csv.gz", ReleaseDateTime: day("2019-09-19T23:00:00Z")},
+		//The delivery without ReleaseDateTime is not limited by the date range
+		{Name: "TYO-2019-09-18-NORMALIZEDMP-Data-1-of-1.csv.gz"},
+	}
+	tests := []struct {
+		name   string
+		filter UserPackageDeliveryFilter
+		want   []string
+	}{
+		{name: "all", want: []string{"ASX", "ASX", "NSQ", "LSE", "TYO"}},
+		{name: "venues", filter: UserPackageDeliveryFilter{Venues: []string{"asx", "LSE"}}, want: []string{"ASX", "ASX", "LSE"}},
+		{name: "from", filter: UserPackageDeliveryFilter{From: testTime(t, "2019-09-18T23:00:00Z")}, want: []string{"ASX", "NSQ", "LSE", "TYO"}},
+		{name: "to", filter: UserPackageDeliveryFilter{To: testTime(t, "2019-09-18T23:00:00Z")}, want: []string{"ASX", "ASX", "NSQ", "TYO"}},
+		{name: "one day", filter: UserPackageDeliveryFilter{
+			Venues: []string{"ASX", "NSQ", "LSE"},
+			From:   testTime(t, "2019-09-18T00:00:00Z"),
+			To:     testTime(t, "2019-09-18T23:59:59Z"),
+		}, want: []string{"ASX", "NSQ"}},
+		{name: "unknown venue", filter: UserPackageDeliveryFilter{Venues: []string{"HKG"}}},
+	}
+	for _, test := range tests {
+		var got []string
+		for _, delivery := range FilterUserPackageDeliveries(deliveries, test.filter) {
+			got = append(got, delivery.Venue())
+		}
+		if strings.Join(got, ",") != strings.Join(test.want, ",") {
+			t.Errorf("%s: venues = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDownloadUserPackageDeliveries(t *testing.T) {
+	contents := map[string][]byte{
+		"0x01": testGzipContent(t, 32*1024),
+		"0x02": testGzipContent(t, 48*1024),
+		"0x03": testGzipContent(t, 64*1024),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for id, content := range contents {
+			if r.URL.Path == "/StandardExtractions/UserPackageDeliveries('"+id+"')/$value" {
+				http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	client := NewClient(server.Client(), server.URL+"/", false)
+	client.Progress = NoProgressReporter{}
+
+	var deliveries []UserPackageDelivery
+	for _, id := range []string{"0x01", "0x02", "0x03"} {
+		sum := md5.Sum(contents[id])
+		deliveries = append(deliveries, UserPackageDelivery{
+			PackageDeliveryId: id,
+			Name:              "ASX-2019-09-18-NORMALIZEDMP-Data-" + id + ".csv.gz",
+			FileSizeBytes:     int64(len(contents[id])),
+			ContentMd5:        hex.EncodeToString(sum[:]),
+		})
+	}
+	//The file of the second delivery is not the file of ContentMd5
+	deliveries[1].ContentMd5 = "0cc175b9c0f1b6a831c399e269772661"
+	outDir := t.TempDir()
+
+	err := client.DownloadUserPackageDeliveries(deliveries, outDir, 1, 2)
+	if err == nil || !strings.HasPrefix(err.Error(), "1 of 3 deliveries failed") {
+		t.Fatalf("DownloadUserPackageDeliveries = %v, want 1 of 3 deliveries failed", err)
+	}
+	var integrityErr *IntegrityError
+	if !errors.As(err, &integrityErr) || !strings.Contains(integrityErr.Reason, "MD5") {
+		t.Errorf("error = %v, want IntegrityError of MD5", err)
+	}
+
+	for i, delivery := range deliveries {
+		outFileName := filepath.Join(outDir, delivery.Name)
+		if i == 1 {
+			//The corrupt file is moved to quarantine by the default CorruptFileAction
+			if _, err := os.Stat(outFileName); err == nil {
+				t.Errorf("%s is kept", outFileName)
+			}
+			if _, err := os.Stat(outFileName + QuarantineFileSuffix); err != nil {
+				t.Errorf("%s is not moved to quarantine: %s", outFileName, err.Error())
+			}
+			continue
+		}
+		checkDownloadedFile(t, outFileName, contents[delivery.PackageDeliveryId])
+	}
+}
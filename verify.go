@@ -0,0 +1,145 @@
+package rthrest
+
+import (
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+)
+
+//CorruptFileAction : This is an enumeration for the action applied to a downloaded file which fails the integrity check
+type CorruptFileAction int
+
+//Available Enumerations for CorruptFileAction
+const (
+	CorruptFileDeleteEnum CorruptFileAction = iota
+	CorruptFileQuarantineEnum
+	CorruptFileKeepEnum
+)
+
+//QuarantineFileSuffix : The suffix of the file moved to quarantine by CorruptFileQuarantineEnum
+const QuarantineFileSuffix = ".corrupt"
+
+//md5ETagReg : S3 ETag is the MD5 of the object unless it is uploaded in multiple parts (the ETag then contains '-')
+var md5ETagReg = regexp.MustCompile(`^"?([0-9a-fA-F]{32})"?$`)
+
+//IntegrityError : The error returned when the downloaded file fails the integrity check.
+//QuarantineFileName is set when the file is moved to quarantine
+type IntegrityError struct {
+	FileName           string
+	Reason             string
+	QuarantineFileName string
+}
+
+//Error : Return the file name and the reason of the failure
+func (e *IntegrityError) Error() string {
+	message := fmt.Sprintf("integrity check failed: %s: %s", e.FileName, e.Reason)
+	if e.QuarantineFileName != "" {
+		message += ", moved to " + e.QuarantineFileName
+	}
+	return message
+}
+
+//MD5FromETag : Return the MD5 in hex from the ETag header. It returns "" if the ETag is not a MD5, e.g. S3 multipart upload
+func MD5FromETag(etag string) string {
+	if strings.HasPrefix(etag, "W/") {
+		return ""
+	}
+	m := md5ETagReg.FindStringSubmatch(etag)
+	if m == nil {
+		return ""
+	}
+	return strings.ToLower(m[1])
+}
+
+//VerifyFile : Verify the downloaded file.
+//If expectedSize is greater than 0, the file size must be equal to expectedSize (e.g. ExtractedFile.Size or Content-Length).
+//If expectedMD5 is not empty, the MD5 of the file must be equal to expectedMD5 (hex).
+//If checkGzip is true, the whole gzip stream is decompressed to verify the CRC and length of every member
+func VerifyFile(fileName string, expectedSize int64, expectedMD5 string, checkGzip bool) error {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fi, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if expectedSize > 0 && fi.Size() != expectedSize {
+		return &IntegrityError{FileName: fileName, Reason: fmt.Sprintf("size is %d, expected %d", fi.Size(), expectedSize)}
+	}
+	if expectedMD5 == "" && !checkGzip {
+		return nil
+	}
+
+	var reader io.Reader = file
+	var md5Hash hash.Hash
+	if expectedMD5 != "" {
+		md5Hash = md5.New()
+		reader = io.TeeReader(file, md5Hash)
+	}
+
+	if checkGzip {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return &IntegrityError{FileName: fileName, Reason: "invalid gzip header: " + err.Error()}
+		}
+		if _, err = io.Copy(ioutil.Discard, gzipReader); err != nil {
+			return &IntegrityError{FileName: fileName, Reason: "invalid gzip stream: " + err.Error()}
+		}
+	}
+
+	if md5Hash != nil {
+		//Read the rest of the file which is not consumed by the gzip reader
+		if _, err = io.Copy(ioutil.Discard, reader); err != nil {
+			return err
+		}
+		actual := hex.EncodeToString(md5Hash.Sum(nil))
+		if !strings.EqualFold(actual, expectedMD5) {
+			return &IntegrityError{FileName: fileName, Reason: fmt.Sprintf("MD5 is %s, expected %s", actual, expectedMD5)}
+		}
+	}
+	return nil
+}
+
+//handleCorruptFile : Apply the CorruptFileAction to the file which fails the integrity check
+func handleCorruptFile(fileName string, outFileName string, action CorruptFileAction, integrityErr *IntegrityError) error {
+	switch action {
+	case CorruptFileDeleteEnum:
+		log.Printf("Delete corrupt file: %s\n", fileName)
+		os.Remove(fileName)
+	case CorruptFileQuarantineEnum:
+		quarantineFileName := outFileName + QuarantineFileSuffix
+		if err := os.Rename(fileName, quarantineFileName); err != nil {
+			return err
+		}
+		integrityErr.QuarantineFileName = quarantineFileName
+	}
+	return integrityErr
+}
+
+//verifyDownload : Verify the temporary file of the completed download by using the settings in Downloader.
+//etag is the ETag header of the response. It is used only when VerifyETag is true
+func (d *Downloader) verifyDownload(fileName string, outFileName string, expectedSize int64, etag string) error {
+	expectedMD5 := ""
+	if d.VerifyETag {
+		expectedMD5 = MD5FromETag(etag)
+	}
+	checkGzip := d.VerifyGzip && strings.HasSuffix(outFileName, ".gz")
+
+	err := VerifyFile(fileName, expectedSize, expectedMD5, checkGzip)
+	if integrityErr, ok := err.(*IntegrityError); ok {
+		integrityErr.FileName = outFileName
+		return handleCorruptFile(fileName, outFileName, d.CorruptFileAction, integrityErr)
+	}
+	return err
+}
@@ -0,0 +1,117 @@
+package rthrest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMD5FromETag(t *testing.T) {
+	tests := []struct {
+		etag string
+		want string
+	}{
+		{etag: `"0CC175B9C0F1B6A831C399E269772661"`, want: "0cc175b9c0f1b6a831c399e269772661"},
+		{etag: "0cc175b9c0f1b6a831c399e269772661", want: "0cc175b9c0f1b6a831c399e269772661"},
+		//S3 multipart upload
+		{etag: `"0cc175b9c0f1b6a831c399e269772661-3"`},
+		{etag: `W/"0cc175b9c0f1b6a831c399e269772661"`},
+		{etag: `"test-etag"`},
+		{etag: ""},
+	}
+	for _, test := range tests {
+		if got := MD5FromETag(test.etag); got != test.want {
+			t.Errorf("MD5FromETag(%q) = %q, want %q", test.etag, got, test.want)
+		}
+	}
+}
+
+func TestVerifyFile(t *testing.T) {
+	content := testGzipContent(t, 16*1024)
+	sum := md5.Sum(content)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "output.csv.gz")
+	if err := ioutil.WriteFile(fileName, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	//The truncated gzip has a valid header but no CRC and length at the end
+	truncatedFileName := filepath.Join(dir, "truncated.csv.gz")
+	if err := ioutil.WriteFile(truncatedFileName, content[:len(content)-4], 0644); err != nil {
+		t.Fatal(err)
+	}
+	notGzipFileName := filepath.Join(dir, "text.csv.gz")
+	if err := ioutil.WriteFile(notGzipFileName, []byte("RIC,Domain\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		fileName     string
+		expectedSize int64
+		expectedMD5  string
+		checkGzip    bool
+		corrupt      bool
+	}{
+		{name: "valid", fileName: fileName, expectedSize: int64(len(content)), expectedMD5: MD5FromETag(etag), checkGzip: true},
+		{name: "no check", fileName: truncatedFileName},
+		{name: "size", fileName: fileName, expectedSize: int64(len(content)) + 1, corrupt: true},
+		{name: "MD5", fileName: fileName, expectedMD5: "0cc175b9c0f1b6a831c399e269772661", corrupt: true},
+		{name: "MD5 of truncated file", fileName: truncatedFileName, expectedMD5: MD5FromETag(etag), corrupt: true},
+		{name: "truncated gzip", fileName: truncatedFileName, checkGzip: true, corrupt: true},
+		{name: "not gzip", fileName: notGzipFileName, checkGzip: true, corrupt: true},
+	}
+	for _, test := range tests {
+		err := VerifyFile(test.fileName, test.expectedSize, test.expectedMD5, test.checkGzip)
+		if _, ok := err.(*IntegrityError); ok != test.corrupt {
+			t.Errorf("%s: VerifyFile = %v, want IntegrityError %v", test.name, err, test.corrupt)
+		}
+	}
+
+	if err := VerifyFile(filepath.Join(dir, "missing.csv.gz"), 0, "", true); err == nil {
+		t.Error("VerifyFile of the missing file: want error")
+	} else if _, ok := err.(*IntegrityError); ok {
+		t.Errorf("VerifyFile of the missing file = %v, want the error of os.Open", err)
+	}
+}
+
+func TestHandleCorruptFile(t *testing.T) {
+	tests := []struct {
+		action     CorruptFileAction
+		kept       bool
+		quarantine bool
+	}{
+		{action: CorruptFileDeleteEnum},
+		{action: CorruptFileQuarantineEnum, quarantine: true},
+		{action: CorruptFileKeepEnum, kept: true},
+	}
+	for _, test := range tests {
+		outFileName := filepath.Join(t.TempDir(), "output.csv.gz")
+		fileName := outFileName + PartialFileSuffix
+		if err := ioutil.WriteFile(fileName, []byte("corrupt"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		err := handleCorruptFile(fileName, outFileName, test.action, &IntegrityError{FileName: outFileName, Reason: "test"})
+		integrityErr, ok := err.(*IntegrityError)
+		if !ok {
+			t.Errorf("action %d: error = %v, want IntegrityError", test.action, err)
+			continue
+		}
+		if _, err := os.Stat(fileName); (err == nil) != test.kept {
+			t.Errorf("action %d: file kept = %v, want %v", test.action, err == nil, test.kept)
+		}
+		data, err := ioutil.ReadFile(outFileName + QuarantineFileSuffix)
+		if test.quarantine {
+			if err != nil || !bytes.Equal(data, []byte("corrupt")) || integrityErr.QuarantineFileName != outFileName+QuarantineFileSuffix {
+				t.Errorf("action %d: quarantine file = %q, %v, QuarantineFileName = %q", test.action, data, err, integrityErr.QuarantineFileName)
+			}
+		} else if err == nil || integrityErr.QuarantineFileName != "" {
+			t.Errorf("action %d: the file is moved to quarantine", test.action)
+		}
+	}
+}